@@ -0,0 +1,150 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultAnalyticsDays is the number of days of the analytics unless the days parameter is given.
+	defaultAnalyticsDays = 30
+	// maxAnalyticsDays is the maximum number of days of the analytics.
+	maxAnalyticsDays = 90
+)
+
+// visitorKey identifies the visitor of a request for deduplicating views:
+// the user if the request has a user ID, or else the client address.
+func visitorKey(r *http.Request, userID int) string {
+	if userID != 0 {
+		return "user:" + strconv.Itoa(userID)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// parseAnalyticsPeriod parses the number of days of the analytics, and returns the first and last day.
+// The last day is today in UTC.
+func parseAnalyticsPeriod(r *http.Request, now time.Time) (from, to time.Time, err error) {
+	days := defaultAnalyticsDays
+	if s := r.URL.Query().Get("days"); s != "" {
+		var v validator
+		days = v.intRange("days", s, 1, maxAnalyticsDays)
+		if err := v.err(); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	to = now.UTC().Truncate(24 * time.Hour)
+	return to.AddDate(0, 0, -(days - 1)), to, nil
+}
+
+// dailySeries fills the days without views between from and to with zeros, and returns the total views.
+func dailySeries(days []DailyViews, from, to time.Time) ([]DailyViews, int) {
+	views := make(map[string]int, len(days))
+	for _, d := range days {
+		views[d.Date] = d.Views
+	}
+
+	series := []DailyViews{}
+	total := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dayLayout)
+		series = append(series, DailyViews{Date: date, Views: views[date]})
+		total += views[date]
+	}
+	return series, total
+}
+
+// GetItemAnalytics is a handler to return the daily views of an item to its seller for GET /items/{item_id}/analytics .
+func (s *Handlers) GetItemAnalytics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	from, to, err := parseAnalyticsPeriod(r, time.Now())
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if item.SellerID != userID {
+		writeError(w, r, newAPIError(http.StatusForbidden, "only the seller can see the analytics"))
+		return
+	}
+
+	days, err := s.analyticsRepo.GetItemDailyViews(ctx, itemID, from, to)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get daily views: %w", err))
+		return
+	}
+	series, total := dailySeries(days, from, to)
+
+	writeJSON(w, http.StatusOK, struct {
+		ItemID     int          `json:"item_id"`
+		TotalViews int          `json:"total_views"`
+		Daily      []DailyViews `json:"daily"`
+	}{ItemID: itemID, TotalViews: total, Daily: series})
+}
+
+// GetSellerAnalytics is a handler to return the daily views of all the items of a seller
+// to the seller for GET /sellers/{seller_id}/analytics .
+func (s *Handlers) GetSellerAnalytics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	sellerID, err := parseSellerID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	if sellerID != userID {
+		writeError(w, r, newAPIError(http.StatusForbidden, "only the seller can see the analytics"))
+		return
+	}
+	from, to, err := parseAnalyticsPeriod(r, time.Now())
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	days, err := s.analyticsRepo.GetSellerDailyViews(ctx, sellerID, from, to)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get daily views: %w", err))
+		return
+	}
+	items, err := s.analyticsRepo.ListSellerItemViews(ctx, sellerID, from, to)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get item views: %w", err))
+		return
+	}
+	series, total := dailySeries(days, from, to)
+
+	writeJSON(w, http.StatusOK, struct {
+		SellerID   int          `json:"seller_id"`
+		TotalViews int          `json:"total_views"`
+		Daily      []DailyViews `json:"daily"`
+		Items      []ItemViews  `json:"items"`
+	}{SellerID: sellerID, TotalViews: total, Daily: series, Items: items})
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// dayLayout is the format of the days of the daily view counts, in UTC.
+const dayLayout = "2006-01-02"
+
+// DailyViews is the number of views on a day.
+type DailyViews struct {
+	Date  string `json:"date"`
+	Views int    `json:"views"`
+}
+
+// ItemViews is the number of views of an item over a period.
+type ItemViews struct {
+	ItemID int    `json:"item_id"`
+	Name   string `json:"name"`
+	Views  int    `json:"views"`
+}
+
+// AnalyticsRepository is an interface to manage the view counts of the items.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type AnalyticsRepository interface {
+	// RecordViews stores the view events and adds them to the daily view counts.
+	RecordViews(ctx context.Context, views []ItemEvent) error
+	// GetItemDailyViews returns the days from from to to, both included, with views of the item.
+	GetItemDailyViews(ctx context.Context, itemID int, from, to time.Time) ([]DailyViews, error)
+	// GetSellerDailyViews returns the days from from to to, both included, with views of the items of the seller.
+	GetSellerDailyViews(ctx context.Context, sellerID int, from, to time.Time) ([]DailyViews, error)
+	// ListSellerItemViews returns the items of the seller viewed from from to to, both included, most viewed first.
+	ListSellerItemViews(ctx context.Context, sellerID int, from, to time.Time) ([]ItemViews, error)
+}
+
+// analyticsRepository is an implementation of AnalyticsRepository
+type analyticsRepository struct {
+	db *sql.DB
+}
+
+// NewAnalyticsRepository creates a new analyticsRepository.
+func NewAnalyticsRepository(database *sql.DB) AnalyticsRepository {
+	return &analyticsRepository{db: database}
+}
+
+func (a *analyticsRepository) RecordViews(ctx context.Context, views []ItemEvent) error {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, v := range views {
+		_, err := tx.ExecContext(ctx, "INSERT INTO item_events (item_id, type, user_id, created_at) VALUES (?, ?, ?, ?)",
+			v.ItemID, ItemEventView, v.UserID, v.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert item event: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO item_daily_views (item_id, day, views) VALUES (?, ?, 1)
+			ON CONFLICT (item_id, day) DO UPDATE SET views = views + 1`,
+			v.ItemID, v.CreatedAt.UTC().Format(dayLayout))
+		if err != nil {
+			return fmt.Errorf("failed to count item view: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// scanDailyViews scans the rows of days and view counts.
+func scanDailyViews(rows *sql.Rows) ([]DailyViews, error) {
+	defer rows.Close()
+
+	days := []DailyViews{}
+	for rows.Next() {
+		var d DailyViews
+		if err := rows.Scan(&d.Date, &d.Views); err != nil {
+			return nil, fmt.Errorf("failed to scan daily views: %w", err)
+		}
+		days = append(days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate daily views: %w", err)
+	}
+	return days, nil
+}
+
+func (a *analyticsRepository) GetItemDailyViews(ctx context.Context, itemID int, from, to time.Time) ([]DailyViews, error) {
+	rows, err := a.db.QueryContext(ctx, "SELECT day, views FROM item_daily_views WHERE item_id = ? AND day BETWEEN ? AND ? ORDER BY day",
+		itemID, from.UTC().Format(dayLayout), to.UTC().Format(dayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily views: %w", err)
+	}
+	return scanDailyViews(rows)
+}
+
+func (a *analyticsRepository) GetSellerDailyViews(ctx context.Context, sellerID int, from, to time.Time) ([]DailyViews, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT item_daily_views.day, SUM(item_daily_views.views)
+		FROM item_daily_views
+		JOIN items ON item_daily_views.item_id = items.id
+		WHERE items.seller_id = ? AND item_daily_views.day BETWEEN ? AND ?
+		GROUP BY item_daily_views.day
+		ORDER BY item_daily_views.day`,
+		sellerID, from.UTC().Format(dayLayout), to.UTC().Format(dayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily views: %w", err)
+	}
+	return scanDailyViews(rows)
+}
+
+func (a *analyticsRepository) ListSellerItemViews(ctx context.Context, sellerID int, from, to time.Time) ([]ItemViews, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT items.id, items.name, SUM(item_daily_views.views)
+		FROM item_daily_views
+		JOIN items ON item_daily_views.item_id = items.id
+		WHERE items.seller_id = ? AND item_daily_views.day BETWEEN ? AND ?
+		GROUP BY items.id
+		ORDER BY SUM(item_daily_views.views) DESC, items.id DESC`,
+		sellerID, from.UTC().Format(dayLayout), to.UTC().Format(dayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item views: %w", err)
+	}
+	defer rows.Close()
+
+	items := []ItemViews{}
+	for rows.Next() {
+		var iv ItemViews
+		if err := rows.Scan(&iv.ItemID, &iv.Name, &iv.Views); err != nil {
+			return nil, fmt.Errorf("failed to scan item views: %w", err)
+		}
+		items = append(items, iv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate item views: %w", err)
+	}
+
+	return items, nil
+}
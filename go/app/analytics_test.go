@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestViewRecorder(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := gomock.NewController(t)
+	mockAR := NewMockAnalyticsRepository(ctrl)
+	mockAR.EXPECT().RecordViews(gomock.Any(), []ItemEvent{
+		{ItemID: 1, Type: ItemEventView, UserID: 2, CreatedAt: now},
+		{ItemID: 1, Type: ItemEventView, CreatedAt: now},
+		{ItemID: 2, Type: ItemEventView, UserID: 2, CreatedAt: now},
+		{ItemID: 1, Type: ItemEventView, UserID: 2, CreatedAt: now.Add(viewDedupWindow)},
+	}).Return(nil)
+
+	recorder := NewViewRecorder(mockAR)
+	recorder.Start(context.Background())
+
+	cases := []struct {
+		itemID, userID int
+		visitor        string
+		at             time.Time
+		want           bool
+	}{
+		{itemID: 1, userID: 2, visitor: "user:2", at: now, want: true},
+		{itemID: 1, userID: 2, visitor: "user:2", at: now.Add(time.Minute), want: false},
+		{itemID: 1, visitor: "addr:192.0.2.1", at: now, want: true},
+		{itemID: 2, userID: 2, visitor: "user:2", at: now, want: true},
+		{itemID: 1, userID: 2, visitor: "user:2", at: now.Add(viewDedupWindow), want: true},
+	}
+	for _, c := range cases {
+		if got := recorder.Record(c.itemID, c.userID, c.visitor, c.at); got != c.want {
+			t.Errorf("Record(%d, %q, %v): expected %v, got %v", c.itemID, c.visitor, c.at, c.want, got)
+		}
+	}
+
+	// the pending views are written when the recorder stops
+	recorder.Stop()
+}
+
+func TestGetItemAnalytics(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code  int
+		total int
+		daily []DailyViews
+	}
+	cases := map[string]struct {
+		userID string
+		// injector is used to inject the expected calls to the mock
+		injector func(i *MockItemRepository, a *MockAnalyticsRepository)
+		wants
+	}{
+		"ok: days without views are zero": {
+			userID: "1",
+			injector: func(i *MockItemRepository, a *MockAnalyticsRepository) {
+				i.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1}, nil)
+				a.EXPECT().GetItemDailyViews(gomock.Any(), 1, gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int, from, to time.Time) ([]DailyViews, error) {
+						return []DailyViews{{Date: from.Format(dayLayout), Views: 4}, {Date: to.Format(dayLayout), Views: 2}}, nil
+					})
+			},
+			wants: wants{code: http.StatusOK, total: 6, daily: []DailyViews{{Views: 4}, {Views: 0}, {Views: 2}}},
+		},
+		"ng: not the seller": {
+			userID: "2",
+			injector: func(i *MockItemRepository, a *MockAnalyticsRepository) {
+				i.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1}, nil)
+			},
+			wants: wants{code: http.StatusForbidden},
+		},
+		"ng: no user": {
+			injector: func(i *MockItemRepository, a *MockAnalyticsRepository) {},
+			wants:    wants{code: http.StatusUnauthorized},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockAR := NewMockAnalyticsRepository(ctrl)
+			tt.injector(mockIR, mockAR)
+			h := &Handlers{itemRepo: mockIR, analyticsRepo: mockAR}
+
+			req := httptest.NewRequest("GET", "/items/1/analytics?days=3", nil)
+			req.SetPathValue("item_id", "1")
+			if tt.userID != "" {
+				req.Header.Set(userIDHeader, tt.userID)
+			}
+			rr := httptest.NewRecorder()
+			h.GetItemAnalytics(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Fatalf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+			if rr.Code != http.StatusOK {
+				return
+			}
+			var resp struct {
+				TotalViews int          `json:"total_views"`
+				Daily      []DailyViews `json:"daily"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.TotalViews != tt.wants.total {
+				t.Errorf("expected %d views, got %d", tt.wants.total, resp.TotalViews)
+			}
+			// the dates are relative to today, so only the counts are compared
+			for k := range resp.Daily {
+				resp.Daily[k].Date = ""
+			}
+			if diff := cmp.Diff(tt.wants.daily, resp.Daily); diff != "" {
+				t.Errorf("unexpected daily views (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAnalyticsRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	itemRepo := &itemRepository{db: db}
+	repo := &analyticsRepository{db: db}
+	ctx := context.Background()
+
+	var items []*Item
+	for _, name := range []string{"jacket", "shoes"} {
+		item := &Item{Name: name, CategoryID: 1, Image: "a.jpg", SellerID: 1}
+		if err := itemRepo.Insert(ctx, item); err != nil {
+			t.Fatalf("failed to insert item: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	day1 := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Hour)
+	err = repo.RecordViews(ctx, []ItemEvent{
+		{ItemID: items[0].ID, CreatedAt: day1},
+		{ItemID: items[0].ID, CreatedAt: day2},
+		{ItemID: items[0].ID, UserID: 3, CreatedAt: day2},
+		{ItemID: items[1].ID, CreatedAt: day2},
+	})
+	if err != nil {
+		t.Fatalf("failed to record views: %v", err)
+	}
+
+	got, err := repo.GetItemDailyViews(ctx, items[0].ID, day1, day2)
+	if err != nil {
+		t.Fatalf("failed to get daily views: %v", err)
+	}
+	if diff := cmp.Diff([]DailyViews{{Date: "2026-01-01", Views: 1}, {Date: "2026-01-02", Views: 2}}, got); diff != "" {
+		t.Errorf("unexpected item daily views (-want +got):\n%s", diff)
+	}
+
+	got, err = repo.GetSellerDailyViews(ctx, 1, day2, day2)
+	if err != nil {
+		t.Fatalf("failed to get daily views: %v", err)
+	}
+	if diff := cmp.Diff([]DailyViews{{Date: "2026-01-02", Views: 3}}, got); diff != "" {
+		t.Errorf("unexpected seller daily views (-want +got):\n%s", diff)
+	}
+
+	itemViews, err := repo.ListSellerItemViews(ctx, 1, day1, day2)
+	if err != nil {
+		t.Fatalf("failed to get item views: %v", err)
+	}
+	want := []ItemViews{{ItemID: items[0].ID, Name: "jacket", Views: 3}, {ItemID: items[1].ID, Name: "shoes", Views: 1}}
+	if diff := cmp.Diff(want, itemViews); diff != "" {
+		t.Errorf("unexpected item views (-want +got):\n%s", diff)
+	}
+}
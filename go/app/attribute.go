@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// attributeParamPrefix is the prefix of the form values and query parameters carrying attributes,
+// such as attr.brand=Apple .
+const attributeParamPrefix = "attr."
+
+// maxAttributeValueLength is the maximum length of an attribute value.
+const maxAttributeValueLength = 200
+
+// parseAttributes collects the attributes from the form values. It returns nil if there are none.
+func parseAttributes(v *validator, form url.Values) map[string]string {
+	var attrs map[string]string
+	for key, values := range form {
+		name, ok := strings.CutPrefix(key, attributeParamPrefix)
+		if !ok {
+			continue
+		}
+		if !v.check(name != "", key, "attribute name is required") ||
+			!v.check(len(values) == 1, key, "attribute %s is given more than once", name) {
+			continue
+		}
+		value := strings.TrimSpace(values[0])
+		if value == "" {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		attrs[name] = value
+	}
+	return attrs
+}
+
+// attributeField is the field of the errors of an attribute, named after its form value.
+func attributeField(name string) string {
+	return attributeParamPrefix + name
+}
+
+// validateAttributes validates the attributes against the schema of the category and returns them normalized.
+// The errors of every invalid attribute are returned at once as a *ValidationError.
+// Drafts are validated with requireAll set to false, since they may not have every attribute yet.
+func validateAttributes(schema []AttributeDefinition, attrs map[string]string, requireAll bool) (map[string]string, error) {
+	var v validator
+	normalized := make(map[string]string, len(attrs))
+	for _, def := range schema {
+		value, ok := attrs[def.Name]
+		if !ok {
+			v.check(!def.Required || !requireAll, attributeField(def.Name), "attribute %s is required", def.Name)
+			continue
+		}
+		if value, ok := def.normalize(&v, value); ok {
+			normalized[def.Name] = value
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(attrs)) {
+		known := slices.ContainsFunc(schema, func(def AttributeDefinition) bool { return def.Name == name })
+		v.check(known, attributeField(name), "unknown attribute %s for the category", name)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// validateItemAttributes validates the attributes of the item against the schema of its category
+// and replaces them with the normalized values. Validation failures are a *ValidationError.
+func (s *Handlers) validateItemAttributes(ctx context.Context, item *Item, requireAll bool) error {
+	// a draft may not have its category yet
+	if item.CategoryID == 0 {
+		if len(item.Attributes) > 0 {
+			return newValidationError("category", "category is required to set attributes")
+		}
+		return nil
+	}
+
+	schema, err := s.attributeSchemaRepo.GetSchema(ctx, item.CategoryID)
+	if err != nil {
+		return err
+	}
+	attrs, err := validateAttributes(schema, item.Attributes, requireAll)
+	if err != nil {
+		return err
+	}
+	item.Attributes = attrs
+	return nil
+}
+
+// normalize validates a value of the attribute and returns it in the stored form.
+// The error of an invalid value is recorded in v.
+func (d *AttributeDefinition) normalize(v *validator, value string) (string, bool) {
+	field := attributeField(d.Name)
+	if !v.check(len(value) <= maxAttributeValueLength, field, "attribute %s must be at most %d characters", d.Name, maxAttributeValueLength) {
+		return "", false
+	}
+
+	switch d.Type {
+	case AttributeTypeInteger:
+		n, err := strconv.Atoi(value)
+		if !v.check(err == nil, field, "attribute %s must be an integer", d.Name) {
+			return "", false
+		}
+		return strconv.Itoa(n), true
+	case AttributeTypeEnum:
+		if !v.check(slices.Contains(d.Options, value), field, "attribute %s must be one of %s", d.Name, strings.Join(d.Options, ", ")) {
+			return "", false
+		}
+	}
+	return value, true
+}
+
+// GetCategoryAttributes is a handler to return the attribute schema of a category for GET /categories/{category_id}/attributes .
+func (s *Handlers) GetCategoryAttributes(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := strconv.Atoi(r.PathValue("category_id"))
+	if err != nil || categoryID < 1 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, "invalid category ID"))
+		return
+	}
+
+	schema, err := s.attributeSchemaRepo.GetSchema(r.Context(), categoryID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get attribute schema: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		CategoryID int                   `json:"category_id"`
+		Attributes []AttributeDefinition `json:"attributes"`
+	}{CategoryID: categoryID, Attributes: schema})
+}
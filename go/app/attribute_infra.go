@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// AttributeType is the type of the values an attribute accepts.
+type AttributeType string
+
+const (
+	AttributeTypeString  AttributeType = "string"
+	AttributeTypeInteger AttributeType = "integer"
+	// AttributeTypeEnum accepts only the values listed in the options of the attribute.
+	AttributeTypeEnum AttributeType = "enum"
+)
+
+// AttributeDefinition is an attribute the items of a category can have, such as the brand of a smartphone.
+type AttributeDefinition struct {
+	Name     string        `db:"name" json:"name"`
+	Type     AttributeType `db:"type" json:"type"`
+	Required bool          `db:"required" json:"required"`
+	Options  []string      `db:"options" json:"options,omitempty"`
+}
+
+// defaultAttributeSchemas are the attribute schemas set up for a new database, by category name.
+var defaultAttributeSchemas = map[string][]AttributeDefinition{
+	"smartphones": {
+		{Name: "brand", Type: AttributeTypeString, Required: true},
+		{Name: "storage_gb", Type: AttributeTypeInteger},
+		{Name: "color", Type: AttributeTypeEnum, Options: []string{"black", "white", "silver", "gold", "blue", "red", "green", "other"}},
+	},
+	"books": {
+		{Name: "author", Type: AttributeTypeString, Required: true},
+		{Name: "isbn", Type: AttributeTypeString},
+	},
+}
+
+// AttributeSchemaRepository is an interface to manage the attributes each category defines.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type AttributeSchemaRepository interface {
+	// GetSchema returns the attributes of the category. A category without a schema has none.
+	GetSchema(ctx context.Context, categoryID int) ([]AttributeDefinition, error)
+	// SetSchema replaces the attributes of the category.
+	SetSchema(ctx context.Context, categoryID int, schema []AttributeDefinition) error
+}
+
+// attributeSchemaRepository is an implementation of AttributeSchemaRepository
+type attributeSchemaRepository struct {
+	db *sql.DB
+}
+
+// NewAttributeSchemaRepository creates a new attributeSchemaRepository.
+func NewAttributeSchemaRepository(database *sql.DB) AttributeSchemaRepository {
+	return &attributeSchemaRepository{db: database}
+}
+
+func (a *attributeSchemaRepository) GetSchema(ctx context.Context, categoryID int) ([]AttributeDefinition, error) {
+	rows, err := a.db.QueryContext(ctx, "SELECT name, type, required, options FROM category_attributes WHERE category_id = ? ORDER BY position", categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attribute schema: %w", err)
+	}
+	defer rows.Close()
+
+	schema := []AttributeDefinition{}
+	for rows.Next() {
+		var (
+			def     AttributeDefinition
+			options string
+		)
+		if err := rows.Scan(&def.Name, &def.Type, &def.Required, &options); err != nil {
+			return nil, fmt.Errorf("failed to scan attribute definition: %w", err)
+		}
+		if err := json.Unmarshal([]byte(options), &def.Options); err != nil {
+			return nil, fmt.Errorf("failed to decode attribute options: %w", err)
+		}
+		schema = append(schema, def)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attribute definitions: %w", err)
+	}
+
+	return schema, nil
+}
+
+func (a *attributeSchemaRepository) SetSchema(ctx context.Context, categoryID int, schema []AttributeDefinition) error {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := setAttributeSchema(ctx, tx, categoryID, schema); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// setAttributeSchema replaces the attributes of the category in the transaction.
+func setAttributeSchema(ctx context.Context, tx *sql.Tx, categoryID int, schema []AttributeDefinition) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM category_attributes WHERE category_id = ?", categoryID); err != nil {
+		return fmt.Errorf("failed to delete attribute schema: %w", err)
+	}
+	for position, def := range schema {
+		options, err := json.Marshal(def.Options)
+		if err != nil {
+			return fmt.Errorf("failed to encode attribute options: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, "INSERT INTO category_attributes (category_id, name, type, required, options, position) VALUES (?, ?, ?, ?, ?, ?)",
+			categoryID, def.Name, def.Type, def.Required, string(options), position)
+		if err != nil {
+			return fmt.Errorf("failed to insert attribute definition: %w", err)
+		}
+	}
+	return nil
+}
+
+// seedAttributeSchemas sets up defaultAttributeSchemas for the categories that do not have a schema yet.
+func seedAttributeSchemas(database *sql.DB) error {
+	ctx := context.Background()
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for category, schema := range defaultAttributeSchemas {
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO categories (name) VALUES (?)", category); err != nil {
+			return fmt.Errorf("failed to insert category: %w", err)
+		}
+		var categoryID, defined int
+		err := tx.QueryRowContext(ctx, `
+			SELECT categories.id, (SELECT COUNT(*) FROM category_attributes WHERE category_attributes.category_id = categories.id)
+			FROM categories WHERE name = ?`, category).Scan(&categoryID, &defined)
+		if err != nil {
+			return fmt.Errorf("failed to get category id: %w", err)
+		}
+		if defined > 0 {
+			continue
+		}
+		if err := setAttributeSchema(ctx, tx, categoryID, schema); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
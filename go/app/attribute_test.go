@@ -0,0 +1,171 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateAttributes(t *testing.T) {
+	t.Parallel()
+
+	schema := defaultAttributeSchemas["smartphones"]
+
+	type wants struct {
+		attrs map[string]string
+		// fields are the invalid fields, in the order they are reported
+		fields []string
+	}
+	cases := map[string]struct {
+		attrs      map[string]string
+		requireAll bool
+		wants
+	}{
+		"ok: normalized": {
+			attrs:      map[string]string{"brand": "Apple", "storage_gb": "0128", "color": "black"},
+			requireAll: true,
+			wants:      wants{attrs: map[string]string{"brand": "Apple", "storage_gb": "128", "color": "black"}},
+		},
+		"ok: draft without required attribute": {
+			attrs: map[string]string{"color": "white"},
+			wants: wants{attrs: map[string]string{"color": "white"}},
+		},
+		"ok: draft without attributes": {
+			wants: wants{attrs: nil},
+		},
+		"ng: required attribute is missing": {
+			attrs:      map[string]string{"color": "white"},
+			requireAll: true,
+			wants:      wants{fields: []string{"attr.brand"}},
+		},
+		"ng: unknown attribute": {
+			attrs:      map[string]string{"brand": "Apple", "author": "Soseki"},
+			requireAll: true,
+			wants:      wants{fields: []string{"attr.author"}},
+		},
+		"ng: not an integer": {
+			attrs:      map[string]string{"brand": "Apple", "storage_gb": "a lot"},
+			requireAll: true,
+			wants:      wants{fields: []string{"attr.storage_gb"}},
+		},
+		"ng: not an option": {
+			attrs:      map[string]string{"brand": "Apple", "color": "purple"},
+			requireAll: true,
+			wants:      wants{fields: []string{"attr.color"}},
+		},
+		"ng: every invalid attribute at once": {
+			attrs:      map[string]string{"storage_gb": "a lot", "color": "purple", "author": "Soseki"},
+			requireAll: true,
+			wants:      wants{fields: []string{"attr.brand", "attr.storage_gb", "attr.color", "attr.author"}},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := validateAttributes(schema, tt.attrs, tt.requireAll)
+			if err != nil {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("expected a *ValidationError, got %v", err)
+				}
+				var fields []string
+				for _, f := range validationErr.Fields {
+					fields = append(fields, f.Field)
+				}
+				if diff := cmp.Diff(tt.wants.fields, fields); diff != "" {
+					t.Errorf("unexpected invalid fields (-want +got):\n%s", diff)
+				}
+				return
+			}
+			if tt.wants.fields != nil {
+				t.Fatalf("expected an error, got %+v", got)
+			}
+			if diff := cmp.Diff(tt.wants.attrs, got); diff != "" {
+				t.Errorf("unexpected attributes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSearchByAttributes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	itemRepo := &itemRepository{db: db}
+	ssRepo := &savedSearchRepository{db: db}
+	ctx := context.Background()
+
+	categoryID, err := itemRepo.GetCategoryID(ctx, "smartphones")
+	if err != nil {
+		t.Fatalf("failed to get category: %v", err)
+	}
+	for _, item := range []*Item{
+		{Name: "iPhone 15", CategoryID: categoryID, Image: "a.jpg", SellerID: 1, Attributes: map[string]string{"brand": "Apple", "storage_gb": "128"}},
+		{Name: "iPhone 15 Pro", CategoryID: categoryID, Image: "a.jpg", SellerID: 1, Attributes: map[string]string{"brand": "Apple", "storage_gb": "256"}},
+		{Name: "Pixel phone", CategoryID: categoryID, Image: "a.jpg", SellerID: 1, Attributes: map[string]string{"brand": "Google", "storage_gb": "128"}},
+	} {
+		if err := itemRepo.Insert(ctx, item); err != nil {
+			t.Fatalf("failed to insert item: %v", err)
+		}
+	}
+
+	rows, err := itemRepo.Search(ctx, &SearchQuery{Keyword: "i", Attributes: map[string]string{"brand": "Apple", "storage_gb": "128"}})
+	if err != nil {
+		t.Fatalf("failed to search items: %v", err)
+	}
+	var names []string
+	for rows.Next() {
+		var it ItemName
+		if err := rows.Scan(&it.ID, &it.Name, &it.Category, &it.Image); err != nil {
+			t.Fatalf("failed to scan item: %v", err)
+		}
+		names = append(names, it.Name)
+	}
+	rows.Close()
+	if diff := cmp.Diff([]string{"iPhone 15"}, names); diff != "" {
+		t.Errorf("unexpected items (-want +got):\n%s", diff)
+	}
+
+	// saved searches apply the same attribute filters
+	for _, s := range []*SavedSearch{
+		{UserID: 2, SearchQuery: SearchQuery{Keyword: "pixel", Attributes: map[string]string{"brand": "Google"}}},
+		{UserID: 3, SearchQuery: SearchQuery{Keyword: "pixel", Attributes: map[string]string{"brand": "Apple"}}},
+		{UserID: 4, SearchQuery: SearchQuery{Keyword: "pixel"}},
+	} {
+		if err := ssRepo.Insert(ctx, s); err != nil {
+			t.Fatalf("failed to insert saved search: %v", err)
+		}
+	}
+	pixel, err := itemRepo.GetByID(ctx, 3)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	matching, err := ssRepo.ListMatching(ctx, pixel, "smartphones")
+	if err != nil {
+		t.Fatalf("failed to list matching searches: %v", err)
+	}
+	var users []int
+	for _, s := range matching {
+		users = append(users, s.UserID)
+	}
+	sort.Ints(users)
+	if diff := cmp.Diff([]int{2, 4}, users); diff != "" {
+		t.Errorf("unexpected matching users (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const maxCommentLength = 1000
+
+type AddCommentRequest struct {
+	ItemID int
+	Body   string `json:"body"`
+}
+
+// parseAddCommentRequest parses and validates the request to post a comment.
+func parseAddCommentRequest(r *http.Request) (*AddCommentRequest, error) {
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &AddCommentRequest{
+		ItemID: itemID,
+		Body:   strings.TrimSpace(r.FormValue("body")),
+	}
+
+	// validate the request
+	var v validator
+	if v.required("body", req.Body) {
+		v.maxLength("body", req.Body, maxCommentLength)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// AddComment is a handler to post a comment on an item for POST /items/{item_id}/comments .
+func (s *Handlers) AddComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	req, err := parseAddCommentRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	// only the seller can comment on an item nobody else can see
+	item, err := s.itemRepo.GetByID(ctx, req.ItemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+
+	comment := &Comment{ItemID: req.ItemID, UserID: userID, Body: req.Body}
+	if err := s.commentRepo.Insert(ctx, comment); err != nil {
+		writeError(w, r, fmt.Errorf("failed to store comment: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+type GetCommentsResponse struct {
+	Comments []Comment `json:"comments"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
+
+// GetComments is a handler to return the comments on an item for GET /items/{item_id}/comments .
+func (s *Handlers) GetComments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	// anonymous users can see the comments of any item they can see
+	userID, err := parseUserID(r)
+	if err != nil && !errors.Is(err, errUserIDRequired) {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+
+	comments, err := s.commentRepo.ListByItemID(ctx, itemID, limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get comments: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GetCommentsResponse{Comments: comments, Limit: limit, Offset: offset})
+}
+
+// DeleteComment is a handler to delete a comment for DELETE /items/{item_id}/comments/{comment_id} .
+// Only the author of the comment or the seller of the item can delete it.
+func (s *Handlers) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	commentID, err := strconv.Atoi(r.PathValue("comment_id"))
+	if err != nil || commentID < 1 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, "invalid comment ID"))
+		return
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get comment: %w", err))
+		return
+	}
+	if comment.ItemID != itemID {
+		writeError(w, r, errCommentNotFound)
+		return
+	}
+
+	if comment.UserID != userID {
+		item, err := s.itemRepo.GetByID(ctx, itemID)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+			return
+		}
+		if item.SellerID != userID {
+			writeError(w, r, newAPIError(http.StatusForbidden, "only the author or the seller can delete the comment"))
+			return
+		}
+	}
+
+	if err := s.commentRepo.Delete(ctx, commentID); err != nil && !errors.Is(err, errCommentNotFound) {
+		writeError(w, r, fmt.Errorf("failed to delete comment: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var errCommentNotFound = errors.New("comment not found")
+
+type Comment struct {
+	ID        int       `db:"id" json:"id"`
+	ItemID    int       `db:"item_id" json:"item_id"`
+	UserID    int       `db:"user_id" json:"user_id"`
+	Body      string    `db:"body" json:"body"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// CommentRepository is an interface to manage comments on items.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type CommentRepository interface {
+	Insert(ctx context.Context, comment *Comment) error
+	GetByID(ctx context.Context, commentID int) (*Comment, error)
+	ListByItemID(ctx context.Context, itemID, limit, offset int) ([]Comment, error)
+	Delete(ctx context.Context, commentID int) error
+}
+
+// commentRepository is an implementation of CommentRepository
+type commentRepository struct {
+	db *sql.DB
+}
+
+// NewCommentRepository creates a new commentRepository.
+func NewCommentRepository(database *sql.DB) CommentRepository {
+	return &commentRepository{db: database}
+}
+
+// Insert inserts a comment and fills its ID and creation time.
+func (c *commentRepository) Insert(ctx context.Context, comment *Comment) error {
+	comment.CreatedAt = time.Now().UTC()
+	res, err := c.db.ExecContext(ctx, "INSERT INTO comments (item_id, user_id, body, created_at) VALUES (?, ?, ?, ?)",
+		comment.ItemID, comment.UserID, comment.Body, comment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert comment: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get comment id: %w", err)
+	}
+	comment.ID = int(id)
+	return nil
+}
+
+func (c *commentRepository) GetByID(ctx context.Context, commentID int) (*Comment, error) {
+	var comment Comment
+	err := c.db.QueryRowContext(ctx, "SELECT id, item_id, user_id, body, created_at FROM comments WHERE id = ?", commentID).
+		Scan(&comment.ID, &comment.ItemID, &comment.UserID, &comment.Body, &comment.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errCommentNotFound
+		}
+		return nil, fmt.Errorf("failed to query comment: %w", err)
+	}
+	return &comment, nil
+}
+
+// ListByItemID returns the comments on an item, oldest first.
+func (c *commentRepository) ListByItemID(ctx context.Context, itemID, limit, offset int) ([]Comment, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT id, item_id, user_id, body, created_at FROM comments
+		WHERE item_id = ?
+		ORDER BY id
+		LIMIT ? OFFSET ?`, itemID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		var comment Comment
+		if err := rows.Scan(&comment.ID, &comment.ItemID, &comment.UserID, &comment.Body, &comment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+func (c *commentRepository) Delete(ctx context.Context, commentID int) error {
+	res, err := c.db.ExecContext(ctx, "DELETE FROM comments WHERE id = ?", commentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	if n == 0 {
+		return errCommentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddComment(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID   string
+		body     string
+		injector func(ir *MockItemRepository, cr *MockCommentRepository)
+		wants
+	}{
+		"ok: comment posted": {
+			userID: "2",
+			body:   "Is this still available?",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Status: ItemStatusOnSale}, nil).Times(1)
+				cr.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			wants: wants{code: http.StatusCreated},
+		},
+		"ng: user ID is missing": {
+			body:     "Is this still available?",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {},
+			wants:    wants{code: http.StatusUnauthorized},
+		},
+		"ng: body is empty": {
+			userID:   "2",
+			body:     "  ",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {},
+			wants:    wants{code: http.StatusUnprocessableEntity},
+		},
+		"ng: item not found": {
+			userID: "2",
+			body:   "Is this still available?",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errItemNotFound).Times(1)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+		"ng: draft of another seller": {
+			userID: "2",
+			body:   "Is this still available?",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Status: ItemStatusDraft}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+		"ng: failed to insert": {
+			userID: "2",
+			body:   "Is this still available?",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Status: ItemStatusOnSale}, nil).Times(1)
+				cr.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(fmt.Errorf("database is locked")).Times(1)
+			},
+			wants: wants{code: http.StatusInternalServerError},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockCR := NewMockCommentRepository(ctrl)
+			tt.injector(mockIR, mockCR)
+			h := &Handlers{itemRepo: mockIR, commentRepo: mockCR}
+
+			form := url.Values{"body": {tt.body}}
+			req := httptest.NewRequest("POST", "/items/1/comments", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("item_id", "1")
+			if tt.userID != "" {
+				req.Header.Set(userIDHeader, tt.userID)
+			}
+
+			rr := httptest.NewRecorder()
+			h.AddComment(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+		})
+	}
+}
+
+func TestGetComments(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID   string
+		item     *Item
+		injector func(cr *MockCommentRepository)
+		wants
+	}{
+		"ok: anonymous user": {
+			item: &Item{ID: 1, SellerID: 1, Status: ItemStatusOnSale},
+			injector: func(cr *MockCommentRepository) {
+				cr.EXPECT().ListByItemID(gomock.Any(), 1, gomock.Any(), gomock.Any()).Return([]Comment{}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusOK},
+		},
+		"ok: seller sees the comments of a quarantined item": {
+			userID: "1",
+			item:   &Item{ID: 1, SellerID: 1, Status: ItemStatusQuarantined},
+			injector: func(cr *MockCommentRepository) {
+				cr.EXPECT().ListByItemID(gomock.Any(), 1, gomock.Any(), gomock.Any()).Return([]Comment{}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusOK},
+		},
+		"ng: quarantined item of another seller": {
+			userID:   "2",
+			item:     &Item{ID: 1, SellerID: 1, Status: ItemStatusQuarantined},
+			injector: func(cr *MockCommentRepository) {},
+			wants:    wants{code: http.StatusNotFound},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockCR := NewMockCommentRepository(ctrl)
+			mockIR.EXPECT().GetByID(gomock.Any(), 1).Return(tt.item, nil).Times(1)
+			tt.injector(mockCR)
+			h := &Handlers{itemRepo: mockIR, commentRepo: mockCR}
+
+			req := httptest.NewRequest("GET", "/items/1/comments", nil)
+			req.SetPathValue("item_id", "1")
+			if tt.userID != "" {
+				req.Header.Set(userIDHeader, tt.userID)
+			}
+
+			rr := httptest.NewRecorder()
+			h.GetComments(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID   string
+		injector func(ir *MockItemRepository, cr *MockCommentRepository)
+		wants
+	}{
+		"ok: deleted by the author": {
+			userID: "2",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {
+				cr.EXPECT().GetByID(gomock.Any(), 10).Return(&Comment{ID: 10, ItemID: 1, UserID: 2}, nil).Times(1)
+				cr.EXPECT().Delete(gomock.Any(), 10).Return(nil).Times(1)
+			},
+			wants: wants{code: http.StatusNoContent},
+		},
+		"ok: deleted by the seller": {
+			userID: "1",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {
+				cr.EXPECT().GetByID(gomock.Any(), 10).Return(&Comment{ID: 10, ItemID: 1, UserID: 2}, nil).Times(1)
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1}, nil).Times(1)
+				cr.EXPECT().Delete(gomock.Any(), 10).Return(nil).Times(1)
+			},
+			wants: wants{code: http.StatusNoContent},
+		},
+		"ng: deleted by another user": {
+			userID: "3",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {
+				cr.EXPECT().GetByID(gomock.Any(), 10).Return(&Comment{ID: 10, ItemID: 1, UserID: 2}, nil).Times(1)
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusForbidden},
+		},
+		"ng: comment on another item": {
+			userID: "2",
+			injector: func(ir *MockItemRepository, cr *MockCommentRepository) {
+				cr.EXPECT().GetByID(gomock.Any(), 10).Return(&Comment{ID: 10, ItemID: 5, UserID: 2}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockCR := NewMockCommentRepository(ctrl)
+			tt.injector(mockIR, mockCR)
+			h := &Handlers{itemRepo: mockIR, commentRepo: mockCR}
+
+			req := httptest.NewRequest("DELETE", "/items/1/comments/10", nil)
+			req.SetPathValue("item_id", "1")
+			req.SetPathValue("comment_id", "10")
+			req.Header.Set(userIDHeader, tt.userID)
+
+			rr := httptest.NewRecorder()
+			h.DeleteComment(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+		})
+	}
+}
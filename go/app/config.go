@@ -0,0 +1,298 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// The environment variables overriding the configuration file.
+const (
+	configFileEnv   = "CONFIG_FILE"
+	portEnv         = "PORT"
+	imageDirPathEnv = "IMAGE_DIR"
+	dbPathEnv       = "DB_PATH"
+	logLevelEnv     = "LOG_LEVEL"
+	frontURLEnv     = "FRONT_URL"
+	// corsOriginsEnv lists the comma-separated origins allowed by CORS in addition to FRONT_URL.
+	corsOriginsEnv = "CORS_ORIGINS"
+	// corsAllowCredentialsEnv is true or false.
+	corsAllowCredentialsEnv = "CORS_ALLOW_CREDENTIALS"
+	// shutdownTimeoutEnv is a duration such as 15s.
+	shutdownTimeoutEnv = "SHUTDOWN_TIMEOUT"
+	traceExporterEnv   = "TRACE_EXPORTER"
+	// adminUserIDsEnv lists the comma-separated IDs of the admin users.
+	adminUserIDsEnv = "ADMIN_USER_IDS"
+)
+
+// Config is the configuration of the API server.
+// The values are taken from the defaults, an optional YAML or TOML file, the environment
+// variables and the command line flags, each overriding the previous ones.
+type Config struct {
+	// Port is the port number to listen on.
+	Port string `yaml:"port" toml:"port"`
+	// ImageDirPath is the path to the directory storing images.
+	ImageDirPath string `yaml:"image_dir" toml:"image_dir"`
+	// DBPath is the path to the SQLite database file.
+	DBPath string `yaml:"db_path" toml:"db_path"`
+	// LogLevel is one of debug, info, warn and error.
+	LogLevel string `yaml:"log_level" toml:"log_level"`
+	// FrontURL is the origin of the frontend allowed by CORS.
+	FrontURL string `yaml:"front_url" toml:"front_url"`
+	// CORSOrigins are the origins allowed by CORS in addition to FrontURL. An origin can have a *
+	// standing for a part of the host or the port, such as https://*.example.com , and * allows any origin.
+	CORSOrigins []string `yaml:"cors_origins" toml:"cors_origins"`
+	// CORSAllowCredentials allows the browsers to send cookies and authorization headers to the API.
+	CORSAllowCredentials bool `yaml:"cors_allow_credentials" toml:"cors_allow_credentials"`
+	// AdminUserIDs are the users allowed to use the admin endpoints.
+	AdminUserIDs []int `yaml:"admin_user_ids" toml:"admin_user_ids"`
+	// ShutdownTimeout is how long the in-flight requests are waited for on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// TraceExporter is where the spans are sent: none, otlp or stdout.
+	// The OTLP collector is set by the standard OTEL_EXPORTER_OTLP_* environment variables.
+	TraceExporter string `yaml:"trace_exporter" toml:"trace_exporter"`
+}
+
+// DefaultConfig returns the configuration used when nothing is set.
+func DefaultConfig() Config {
+	return Config{
+		Port:         "9000",
+		ImageDirPath: "images",
+		DBPath:       "db/mercari.sqlite3",
+		LogLevel:     "info",
+		FrontURL:     "http://localhost:3000",
+
+		ShutdownTimeout: 15 * time.Second,
+		TraceExporter:   traceExporterNone,
+	}
+}
+
+// LoadConfig loads the configuration from the command line arguments without the program name
+// and the environment looked up with lookupEnv. It returns whether --print-config was given,
+// and flag.ErrHelp if the usage was requested.
+func LoadConfig(args []string, lookupEnv func(string) (string, bool), output io.Writer) (cfg Config, printConfig bool, err error) {
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	fs.SetOutput(output)
+	var (
+		configFile   = fs.String("config", "", "path to a YAML or TOML configuration file (env "+configFileEnv+")")
+		port         = fs.String("port", "", "port number to listen on (env "+portEnv+")")
+		imageDirPath = fs.String("image-dir", "", "directory storing images (env "+imageDirPathEnv+")")
+		dbPath       = fs.String("db-path", "", "path to the SQLite database file (env "+dbPathEnv+")")
+		logLevel     = fs.String("log-level", "", "debug, info, warn or error (env "+logLevelEnv+")")
+		frontURL     = fs.String("front-url", "", "origin of the frontend allowed by CORS (env "+frontURLEnv+")")
+		adminUserIDs = fs.String("admin-user-ids", "", "comma-separated IDs of the admin users (env "+adminUserIDsEnv+")")
+		corsOrigins  = fs.String("cors-origins", "", "comma-separated origins allowed by CORS in addition to the frontend (env "+corsOriginsEnv+")")
+
+		corsAllowCredentials *bool
+
+		traceExporter   = fs.String("trace-exporter", "", "none, otlp or stdout (env "+traceExporterEnv+")")
+		shutdownTimeout = fs.Duration("shutdown-timeout", 0, "how long the in-flight requests are waited for on shutdown (env "+shutdownTimeoutEnv+")")
+	)
+	fs.BoolVar(&printConfig, "print-config", false, "print the effective configuration and exit")
+	fs.BoolFunc("cors-allow-credentials", "allow credentialed CORS requests (env "+corsAllowCredentialsEnv+")", func(v string) error {
+		b, err := strconv.ParseBool(v)
+		corsAllowCredentials = &b
+		return err
+	})
+	if err := fs.Parse(args); err != nil {
+		return Config{}, false, err
+	}
+	if fs.NArg() > 0 {
+		return Config{}, false, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	cfg = DefaultConfig()
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv(configFileEnv)
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, false, err
+		}
+	}
+
+	// the flags that were not set are empty, so they don't override anything
+	for _, o := range []struct {
+		env  string
+		flag string
+		dst  *string
+	}{
+		{env: portEnv, flag: *port, dst: &cfg.Port},
+		{env: imageDirPathEnv, flag: *imageDirPath, dst: &cfg.ImageDirPath},
+		{env: dbPathEnv, flag: *dbPath, dst: &cfg.DBPath},
+		{env: logLevelEnv, flag: *logLevel, dst: &cfg.LogLevel},
+		{env: frontURLEnv, flag: *frontURL, dst: &cfg.FrontURL},
+		{env: traceExporterEnv, flag: *traceExporter, dst: &cfg.TraceExporter},
+	} {
+		if v, ok := lookupEnv(o.env); ok && v != "" {
+			*o.dst = v
+		}
+		if o.flag != "" {
+			*o.dst = o.flag
+		}
+	}
+
+	ids, ok := lookupEnv(adminUserIDsEnv)
+	if *adminUserIDs != "" {
+		ids, ok = *adminUserIDs, true
+	}
+	if ok && ids != "" {
+		parsed, err := parseAdminUserIDs(ids)
+		if err != nil {
+			return Config{}, false, err
+		}
+		cfg.AdminUserIDs = parsed
+	}
+
+	origins, _ := lookupEnv(corsOriginsEnv)
+	if *corsOrigins != "" {
+		origins = *corsOrigins
+	}
+	if origins != "" {
+		cfg.CORSOrigins = splitList(origins)
+	}
+
+	if v, ok := lookupEnv(corsAllowCredentialsEnv); ok && v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, false, fmt.Errorf("invalid %s: %w", corsAllowCredentialsEnv, err)
+		}
+		cfg.CORSAllowCredentials = b
+	}
+	if corsAllowCredentials != nil {
+		cfg.CORSAllowCredentials = *corsAllowCredentials
+	}
+
+	if v, ok := lookupEnv(shutdownTimeoutEnv); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, false, fmt.Errorf("invalid %s: %w", shutdownTimeoutEnv, err)
+		}
+		cfg.ShutdownTimeout = d
+	}
+	if *shutdownTimeout != 0 {
+		cfg.ShutdownTimeout = *shutdownTimeout
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, false, err
+	}
+	return cfg, printConfig, nil
+}
+
+// loadFile overrides the configuration with a YAML or TOML file, chosen by the extension.
+// Unknown keys are errors, so that typos don't go unnoticed.
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(f)
+		dec.KnownFields(true)
+		// an empty file is not an error
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.NewDecoder(f).Decode(c)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("failed to parse config file %s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("unsupported config file extension %q: use .yaml, .yml or .toml", ext)
+	}
+	return nil
+}
+
+// Validate reports all the invalid values of the configuration.
+func (c Config) Validate() error {
+	var errs []error
+	if n, err := strconv.Atoi(c.Port); err != nil || n < 1 || n > 65535 {
+		errs = append(errs, fmt.Errorf("port must be a number between 1 and 65535: %q", c.Port))
+	}
+	if c.ImageDirPath == "" {
+		errs = append(errs, errors.New("image_dir must not be empty"))
+	}
+	if c.DBPath == "" {
+		errs = append(errs, errors.New("db_path must not be empty"))
+	}
+	if _, err := c.slogLevel(); err != nil {
+		errs = append(errs, fmt.Errorf("log_level must be debug, info, warn or error: %q", c.LogLevel))
+	}
+	if u, err := url.Parse(c.FrontURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("front_url must be an http or https URL: %q", c.FrontURL))
+	}
+	for _, origin := range c.CORSOrigins {
+		if err := validateOrigin(origin); err != nil {
+			errs = append(errs, fmt.Errorf("cors_origins: %w", err))
+		}
+		if origin == "*" && c.CORSAllowCredentials {
+			errs = append(errs, errors.New("cors_origins must not allow any origin with cors_allow_credentials"))
+		}
+	}
+	for _, id := range c.AdminUserIDs {
+		if id < 1 {
+			errs = append(errs, fmt.Errorf("admin_user_ids must be positive: %d", id))
+		}
+	}
+	switch c.TraceExporter {
+	case traceExporterNone, traceExporterOTLP, traceExporterStdout:
+	default:
+		errs = append(errs, fmt.Errorf("trace_exporter must be none, otlp or stdout: %q", c.TraceExporter))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("shutdown_timeout must be positive: %s", c.ShutdownTimeout))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// slogLevel returns the log level as a slog.Level.
+func (c Config) slogLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(c.LogLevel))
+	return level, err
+}
+
+// Print writes the configuration as YAML, which can be used as a configuration file.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return fmt.Errorf("failed to print config: %w", err)
+	}
+	return enc.Close()
+}
+
+// splitList splits a comma-separated list, dropping the empty elements.
+func splitList(v string) []string {
+	var list []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	yamlFile := writeFile("config.yaml", "port: \"8000\"\ndb_path: /data/file.sqlite3\nlog_level: warn\nadmin_user_ids: [1, 2]\nshutdown_timeout: 30s\n")
+	tomlFile := writeFile("config.toml", "port = \"8100\"\nimage_dir = \"/data/images\"\nshutdown_timeout = \"5s\"\n")
+	typoFile := writeFile("typo.yaml", "prot: \"8000\"\n")
+
+	type wants struct {
+		cfg         Config
+		printConfig bool
+		err         string
+	}
+	cases := map[string]struct {
+		args []string
+		env  map[string]string
+		wants
+	}{
+		"ok: defaults": {
+			wants: wants{cfg: DefaultConfig()},
+		},
+		"ok: file, env and flags in order of precedence": {
+			args: []string{"--config", yamlFile, "--log-level", "debug", "--shutdown-timeout", "1m"},
+			env:  map[string]string{portEnv: "8001", logLevelEnv: "error", shutdownTimeoutEnv: "45s", traceExporterEnv: "otlp"},
+			wants: wants{cfg: Config{
+				Port:         "8001",
+				ImageDirPath: "images",
+				DBPath:       "/data/file.sqlite3",
+				LogLevel:     "debug",
+				FrontURL:     "http://localhost:3000",
+				AdminUserIDs: []int{1, 2},
+
+				ShutdownTimeout: time.Minute,
+				TraceExporter:   "otlp",
+			}},
+		},
+		"ok: toml file from env": {
+			args: []string{"--print-config"},
+			env:  map[string]string{configFileEnv: tomlFile, adminUserIDsEnv: "7"},
+			wants: wants{printConfig: true, cfg: Config{
+				Port:         "8100",
+				ImageDirPath: "/data/images",
+				DBPath:       "db/mercari.sqlite3",
+				LogLevel:     "info",
+				FrontURL:     "http://localhost:3000",
+				AdminUserIDs: []int{7},
+
+				ShutdownTimeout: 5 * time.Second,
+				TraceExporter:   "none",
+			}},
+		},
+		"ng: unknown key": {
+			args:  []string{"--config", typoFile},
+			wants: wants{err: "field prot not found"},
+		},
+		"ng: all invalid values are reported": {
+			args:  []string{"--port", "99999", "--front-url", "localhost:3000", "--trace-exporter", "jaeger"},
+			wants: wants{err: "port must be a number between 1 and 65535: \"99999\"\nfront_url must be an http or https URL: \"localhost:3000\"\ntrace_exporter must be none, otlp or stdout"},
+		},
+		"ok: CORS origins from flags": {
+			args: []string{"--cors-origins", "https://*.example.com, http://localhost:*", "--cors-allow-credentials"},
+			env:  map[string]string{corsOriginsEnv: "https://example.com", corsAllowCredentialsEnv: "false"},
+			wants: wants{cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.CORSOrigins = []string{"https://*.example.com", "http://localhost:*"}
+				cfg.CORSAllowCredentials = true
+				return cfg
+			}()},
+		},
+		"ng: invalid CORS origins": {
+			env:   map[string]string{corsOriginsEnv: "https://example.com/app,*", corsAllowCredentialsEnv: "true"},
+			wants: wants{err: "cors_origins: origin must be an http or https origin without a path: \"https://example.com/app\"\ncors_origins must not allow any origin with cors_allow_credentials"},
+		},
+		"ng: invalid shutdown timeout": {
+			env:   map[string]string{shutdownTimeoutEnv: "15"},
+			wants: wants{err: "invalid SHUTDOWN_TIMEOUT"},
+		},
+		"ng: missing file": {
+			args:  []string{"--config", filepath.Join(dir, "missing.yaml")},
+			wants: wants{err: "failed to open config file"},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookupEnv := func(key string) (string, bool) {
+				v, ok := tt.env[key]
+				return v, ok
+			}
+			cfg, printConfig, err := LoadConfig(tt.args, lookupEnv, io.Discard)
+			if tt.wants.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wants.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.wants.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.wants.cfg, cfg); diff != "" {
+				t.Errorf("unexpected config (-want +got):\n%s", diff)
+			}
+			if printConfig != tt.wants.printConfig {
+				t.Errorf("expected printConfig %v, got %v", tt.wants.printConfig, printConfig)
+			}
+		})
+	}
+}
+
+func TestLoadConfigHelp(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	_, _, err := LoadConfig([]string{"--help"}, func(string) (string, bool) { return "", false }, &out)
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected flag.ErrHelp, got %v", err)
+	}
+	if !strings.Contains(out.String(), "-print-config") {
+		t.Errorf("expected the usage, got %q", out.String())
+	}
+}
+
+func TestConfigPrint(t *testing.T) {
+	t.Parallel()
+
+	// the printed configuration can be loaded back as a file
+	cfg := DefaultConfig()
+	cfg.AdminUserIDs = []int{3}
+	cfg.CORSOrigins = []string{"https://*.example.com"}
+	cfg.CORSAllowCredentials = true
+	path := filepath.Join(t.TempDir(), "printed.yaml")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := cfg.Print(f); err != nil {
+		t.Fatalf("failed to print config: %v", err)
+	}
+	f.Close()
+
+	got, _, err := LoadConfig([]string{"--config", path}, func(string) (string, bool) { return "", false }, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to load printed config: %v", err)
+	}
+	if diff := cmp.Diff(cfg, got); diff != "" {
+		t.Errorf("unexpected config (-want +got):\n%s", diff)
+	}
+}
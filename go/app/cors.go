@@ -0,0 +1,141 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsMaxAge is how long the browsers cache the results of the preflight requests.
+const corsMaxAge = 10 * time.Minute
+
+var (
+	// corsMethods are the methods checked against the routes to answer the preflight requests.
+	corsMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}
+	// corsAllowedHeaders are the request headers the frontend may send.
+	corsAllowedHeaders = []string{"Content-Type", userIDHeader, requestIDHeader, "Traceparent", "Tracestate"}
+	// corsExposedHeaders are the response headers the frontend may read.
+	corsExposedHeaders = []string{requestIDHeader, "Retry-After", "RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"}
+)
+
+// CORSPolicy decides the origins allowed to call the API from a browser.
+type CORSPolicy struct {
+	// origins are the allowed origins, such as https://example.com ,
+	// and the patterns with a * standing for a part of the host or the port, such as https://*.example.com .
+	origins []string
+	// allowCredentials allows the requests with cookies or authorization headers.
+	allowCredentials bool
+}
+
+// NewCORSPolicy creates a policy allowing the origins, which must be valid as told by validateOrigin.
+func NewCORSPolicy(origins []string, allowCredentials bool) *CORSPolicy {
+	return &CORSPolicy{origins: origins, allowCredentials: allowCredentials}
+}
+
+// validateOrigin checks an allowed origin of the configuration: "*", an http or https origin
+// without a path, or such an origin with one * in the host or the port.
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	if strings.Count(origin, "*") > 1 {
+		return fmt.Errorf("origin must have at most one *: %q", origin)
+	}
+	u, err := url.Parse(strings.Replace(origin, "*", "0", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("origin must be an http or https origin without a path: %q", origin)
+	}
+	return nil
+}
+
+// allowed reports whether the policy allows the origin.
+func (p *CORSPolicy) allowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	return slices.ContainsFunc(p.origins, func(pattern string) bool {
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		prefix, suffix, ok := strings.Cut(pattern, "*")
+		if !ok || len(origin) <= len(prefix)+len(suffix) ||
+			!strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			return false
+		}
+		// the * stands for labels of the host or digits of the port, never for another host
+		wildcard := origin[len(prefix) : len(origin)-len(suffix)]
+		return !strings.ContainsAny(wildcard, "/:@?#")
+	})
+}
+
+// routeMethods returns the methods the ServeMux has a route for at the path of the request,
+// and the pattern of the first one. It returns no methods if no route matches the path.
+func routeMethods(mux *http.ServeMux, r *http.Request) (methods []string, pattern string) {
+	for _, method := range corsMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, p := mux.Handler(probe); p != "" {
+			methods = append(methods, method)
+			if pattern == "" {
+				pattern = p
+			}
+		}
+	}
+	return methods, pattern
+}
+
+// corsMiddleware applies the CORS policy. The preflight requests are answered with the methods
+// the route of the path supports, or 404 if there is no such route, and never reach next.
+// The responses of the allowed origins can be read by the frontend, with the headers in corsExposedHeaders.
+func corsMiddleware(next http.Handler, mux *http.ServeMux, policy *CORSPolicy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		// the responses depend on the origin, so the caches must not share them between origins
+		h.Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		allowed := policy.allowed(origin)
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+
+			methods, pattern := routeMethods(mux, r)
+			// the mux doesn't see the request, so the pattern is set for the logs and the metrics
+			r.Pattern = pattern
+			if len(methods) == 0 {
+				writeError(w, r, newAPIError(http.StatusNotFound, "not found"))
+				return
+			}
+			if !allowed {
+				writeError(w, r, newAPIError(http.StatusForbidden, "origin not allowed"))
+				return
+			}
+			setCORSOrigin(h, origin, policy)
+			h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			h.Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed {
+			setCORSOrigin(h, origin, policy)
+			h.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setCORSOrigin allows the origin to read the response. The origin is echoed rather than *,
+// which browsers reject on credentialed requests.
+func setCORSOrigin(h http.Header, origin string, policy *CORSPolicy) {
+	h.Set("Access-Control-Allow-Origin", origin)
+	if policy.allowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
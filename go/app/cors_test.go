@@ -0,0 +1,114 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSPolicyAllowed(t *testing.T) {
+	t.Parallel()
+
+	policy := NewCORSPolicy([]string{"http://localhost:3000", "https://*.example.com", "http://127.0.0.1:*"}, false)
+	cases := map[string]struct {
+		origin string
+		want   bool
+	}{
+		"ok: exact origin":          {origin: "http://localhost:3000", want: true},
+		"ok: subdomain":             {origin: "https://app.example.com", want: true},
+		"ok: nested subdomain":      {origin: "https://a.b.example.com", want: true},
+		"ok: any port":              {origin: "http://127.0.0.1:8080", want: true},
+		"ng: other port":            {origin: "http://localhost:3001"},
+		"ng: other scheme":          {origin: "http://app.example.com"},
+		"ng: bare domain":           {origin: "https://example.com"},
+		"ng: other host in pattern": {origin: "https://evil.com/.example.com"},
+		"ng: host after port":       {origin: "http://127.0.0.1:80@evil.com"},
+		"ng: no origin":             {origin: ""},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := policy.allowed(tt.origin); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	mux.HandleFunc("GET /items/{item_id}", ok)
+	mux.HandleFunc("PATCH /items/{item_id}", ok)
+	handler := corsMiddleware(mux, mux, NewCORSPolicy([]string{"http://localhost:3000"}, true))
+
+	type wants struct {
+		code   int
+		origin string
+		// methods is Access-Control-Allow-Methods
+		methods string
+	}
+	cases := map[string]struct {
+		method        string
+		path          string
+		origin        string
+		requestMethod string
+		wants
+	}{
+		"ok: preflight": {
+			method: "OPTIONS", path: "/items/1", origin: "http://localhost:3000", requestMethod: "PATCH",
+			wants: wants{code: http.StatusNoContent, origin: "http://localhost:3000", methods: "GET, HEAD, PATCH"},
+		},
+		"ok: request": {
+			method: "GET", path: "/items/1", origin: "http://localhost:3000",
+			wants: wants{code: http.StatusOK, origin: "http://localhost:3000"},
+		},
+		"ok: request of another origin is served without CORS headers": {
+			method: "GET", path: "/items/1", origin: "https://evil.com",
+			wants: wants{code: http.StatusOK},
+		},
+		"ng: preflight of unknown path": {
+			method: "OPTIONS", path: "/unknown", origin: "http://localhost:3000", requestMethod: "GET",
+			wants: wants{code: http.StatusNotFound},
+		},
+		"ng: preflight of another origin": {
+			method: "OPTIONS", path: "/items/1", origin: "https://evil.com", requestMethod: "PATCH",
+			wants: wants{code: http.StatusForbidden},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			h := rr.Header()
+			if rr.Code != tt.wants.code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+			if got := h.Get("Access-Control-Allow-Origin"); got != tt.wants.origin {
+				t.Errorf("expected allowed origin %q, got %q", tt.wants.origin, got)
+			}
+			if got := h.Get("Access-Control-Allow-Methods"); got != tt.wants.methods {
+				t.Errorf("expected allowed methods %q, got %q", tt.wants.methods, got)
+			}
+			if h.Get("Vary") != "Origin" {
+				t.Errorf("expected Vary: Origin, got %v", h.Values("Vary"))
+			}
+			if tt.wants.origin != "" && h.Get("Access-Control-Allow-Credentials") != "true" {
+				t.Errorf("expected credentials to be allowed, got %v", h)
+			}
+		})
+	}
+}
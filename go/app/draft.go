@@ -0,0 +1,298 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// publishInterval is how often the scheduled drafts are checked for publishing.
+const publishInterval = 30 * time.Second
+
+// isDraftRequest reports whether POST /items asks to save the item as a draft.
+func isDraftRequest(r *http.Request) bool {
+	draft, _ := strconv.ParseBool(r.FormValue("draft"))
+	return draft
+}
+
+// parseOptionalImage reads the image of the request if one is attached.
+func parseOptionalImage(v *validator, r *http.Request) []byte {
+	file, header, err := r.FormFile("image")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return nil
+	}
+	if !v.check(err == nil, "image", "image cannot be read") {
+		return nil
+	}
+	defer file.Close()
+	return v.image("image", file, header)
+}
+
+// parsePublishAt parses the time to publish a draft at, which must be in the future.
+func parsePublishAt(v *validator, s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if !v.check(err == nil, "publish_at", "publish_at must be an RFC 3339 time") ||
+		!v.check(t.After(time.Now()), "publish_at", "publish_at must be in the future") {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// parseAddDraftRequest parses the request to save a draft.
+// Unlike parseAddItemRequest, every field is optional so that the seller can fill them in later.
+func parseAddDraftRequest(r *http.Request) (*AddItemRequest, []byte, *time.Time, error) {
+	err := r.ParseMultipartForm(10 << 20)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil, nil, fmt.Errorf("failed to parse form data: %w", err)
+	}
+
+	req := &AddItemRequest{
+		Name:     strings.TrimSpace(r.FormValue("name")),
+		Category: r.FormValue("category"),
+	}
+
+	var v validator
+	v.maxLength("name", req.Name, maxItemNameLength)
+	if s := r.FormValue("price"); s != "" {
+		req.Price = v.intRange("price", s, 0, noLimit)
+	}
+
+	var publishAt *time.Time
+	if s := r.FormValue("publish_at"); s != "" {
+		publishAt = parsePublishAt(&v, s)
+	}
+
+	image := parseOptionalImage(&v, r)
+	req.Attributes = parseAttributes(&v, r.PostForm)
+
+	if err := v.err(); err != nil {
+		return nil, nil, nil, err
+	}
+	return req, image, publishAt, nil
+}
+
+// errIncompleteDraft returns the error telling which fields a draft needs before it is published.
+func errIncompleteDraft(missing []string) error {
+	var v validator
+	for _, field := range missing {
+		v.addError(field, "%s is required to publish the draft", field)
+	}
+	return v.err()
+}
+
+// missingDraftFields returns the fields the draft needs before it is published,
+// apart from the image when the request sends one that is not stored yet.
+func missingDraftFields(item *Item, hasImage bool) []string {
+	missing := item.missingFields()
+	if hasImage {
+		missing = slices.DeleteFunc(missing, func(field string) bool { return field == "image" })
+	}
+	return missing
+}
+
+// addDraft is a handler to save a draft for POST /items with draft=true .
+func (s *Handlers) addDraft(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// unlike listings, a draft needs its owner to be edited later
+	sellerID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	req, imageData, publishAt, err := parseAddDraftRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item := &Item{
+		Name:      req.Name,
+		SellerID:  sellerID,
+		Price:     req.Price,
+		Status:    ItemStatusDraft,
+		PublishAt: publishAt,
+
+		Attributes: req.Attributes,
+	}
+	if req.Category != "" {
+		item.CategoryID, err = s.itemRepo.GetCategoryID(ctx, req.Category)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("failed to get category ID: %w", err))
+			return
+		}
+	}
+
+	// a scheduled draft must be ready to be listed
+	if missing := missingDraftFields(item, imageData != nil); item.PublishAt != nil && len(missing) > 0 {
+		writeError(w, r, errIncompleteDraft(missing))
+		return
+	}
+	if err := s.validateItemAttributes(ctx, item, item.PublishAt != nil); err != nil {
+		writeError(w, r, fmt.Errorf("failed to validate attributes: %w", err))
+		return
+	}
+
+	// the image is stored only once the draft has been accepted, so that a rejected one leaves no file
+	if imageData != nil {
+		item.Image, err = s.storeImage(ctx, imageData)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("failed to store image: %w", err))
+			return
+		}
+	}
+
+	if err := s.itemRepo.Insert(ctx, item); err != nil {
+		writeError(w, r, fmt.Errorf("failed to store draft: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, item)
+}
+
+// GetDrafts is a handler to return the drafts of the user for GET /drafts .
+func (s *Handlers) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	items, err := s.itemRepo.ListDrafts(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get drafts: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Items []Item `json:"items"`
+	}{Items: items})
+}
+
+// PublishItem is a handler for the seller to list a draft now for POST /items/{item_id}/publish .
+func (s *Handlers) PublishItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+	if item.SellerID != userID {
+		writeError(w, r, newAPIError(http.StatusForbidden, "only the seller can publish the item"))
+		return
+	}
+	if item.Status != ItemStatusDraft {
+		writeError(w, r, errItemNotDraft)
+		return
+	}
+	if missing := item.missingFields(); len(missing) > 0 {
+		writeError(w, r, errIncompleteDraft(missing))
+		return
+	}
+	if err := s.validateItemAttributes(ctx, item, true); err != nil {
+		writeError(w, r, fmt.Errorf("failed to validate attributes: %w", err))
+		return
+	}
+
+	// a flagged draft goes to the review queue instead of being listed
+	quarantined, err := s.quarantineIfFlagged(ctx, item)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to moderate item: %w", err))
+		return
+	}
+	if quarantined {
+		writeJSON(w, http.StatusAccepted, item)
+		return
+	}
+
+	if err := s.itemRepo.Publish(ctx, itemID); err != nil {
+		writeError(w, r, fmt.Errorf("failed to publish item: %w", err))
+		return
+	}
+
+	item, err = s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+
+	// notifications are best effort and must not fail the listing
+	if err := s.notifyPublished(ctx, item); err != nil {
+		slog.ErrorContext(ctx, "failed to notify saved searches: ", "error", err)
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
+// publishScheduledItems publishes the drafts whose publish_at has come.
+// It is run periodically by the scheduler started in Server.Run.
+// Each draft is moderated before it is listed, and goes straight from a draft to the review queue
+// if it is flagged. A draft failing moderation stays a draft and is tried again on the next run.
+func (s *Handlers) publishScheduledItems(ctx context.Context) {
+	items, err := s.itemRepo.ListDue(ctx, time.Now().UTC())
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get scheduled items: ", "error", err)
+		return
+	}
+
+	for k := range items {
+		item := &items[k]
+		reasons, err := s.checkModeration(ctx, item)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to moderate item: ", "error", err, "item_id", item.ID)
+			continue
+		}
+		if len(reasons) > 0 {
+			if _, err := s.moderationRepo.Quarantine(ctx, item.ID, reasons); err != nil {
+				slog.ErrorContext(ctx, "failed to quarantine item: ", "error", err, "item_id", item.ID)
+				continue
+			}
+			slog.InfoContext(ctx, "item quarantined", "item_id", item.ID, "reasons", reasons)
+			continue
+		}
+
+		if err := s.itemRepo.Publish(ctx, item.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to publish scheduled item: ", "error", err, "item_id", item.ID)
+			continue
+		}
+		item.Status = ItemStatusOnSale
+		item.PublishAt = nil
+		slog.InfoContext(ctx, "published scheduled item", "item_id", item.ID)
+
+		if err := s.notifyPublished(ctx, item); err != nil {
+			slog.ErrorContext(ctx, "failed to notify saved searches: ", "error", err, "item_id", item.ID)
+		}
+	}
+}
+
+// notifyPublished notifies the saved searches matching a draft that has just been listed.
+func (s *Handlers) notifyPublished(ctx context.Context, item *Item) error {
+	category, err := s.itemRepo.GetCategoryName(ctx, item.CategoryID)
+	if err != nil {
+		return err
+	}
+	return s.notifySavedSearches(ctx, item, category)
+}
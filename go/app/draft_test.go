@@ -0,0 +1,285 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddDraft(t *testing.T) {
+	t.Parallel()
+
+	future := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID string
+		form   url.Values
+		// injector is used to inject the expected calls to the mock
+		injector func(m *MockItemRepository)
+		wants
+	}{
+		"ok: empty draft": {
+			userID: "1",
+			form:   url.Values{"draft": {"true"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().Insert(gomock.Any(), &Item{SellerID: 1, Status: ItemStatusDraft}).Return(nil)
+			},
+			wants: wants{code: http.StatusCreated},
+		},
+		"ok: name and category only": {
+			userID: "1",
+			form:   url.Values{"draft": {"true"}, "name": {"jacket"}, "category": {"fashion"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetCategoryID(gomock.Any(), "fashion").Return(2, nil)
+				m.EXPECT().Insert(gomock.Any(), &Item{Name: "jacket", CategoryID: 2, SellerID: 1, Status: ItemStatusDraft}).Return(nil)
+			},
+			wants: wants{code: http.StatusCreated},
+		},
+		"ng: scheduled without image": {
+			userID: "1",
+			form:   url.Values{"draft": {"true"}, "name": {"jacket"}, "category": {"fashion"}, "publish_at": {future.Format(time.RFC3339)}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetCategoryID(gomock.Any(), "fashion").Return(2, nil)
+			},
+			wants: wants{code: http.StatusUnprocessableEntity},
+		},
+		"ng: publish_at in the past": {
+			userID:   "1",
+			form:     url.Values{"draft": {"true"}, "publish_at": {"2020-01-01T00:00:00Z"}},
+			injector: func(m *MockItemRepository) {},
+			wants:    wants{code: http.StatusUnprocessableEntity},
+		},
+		"ng: no user": {
+			form:     url.Values{"draft": {"true"}},
+			injector: func(m *MockItemRepository) {},
+			wants:    wants{code: http.StatusUnauthorized},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			tt.injector(mockIR)
+			mockASR := NewMockAttributeSchemaRepository(ctrl)
+			mockASR.EXPECT().GetSchema(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+			h := &Handlers{itemRepo: mockIR, attributeSchemaRepo: mockASR}
+
+			req := httptest.NewRequest("POST", "/items", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.userID != "" {
+				req.Header.Set(userIDHeader, tt.userID)
+			}
+
+			rr := httptest.NewRecorder()
+			h.AddItem(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestPublishScheduledItems(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		published     bool
+		quarantined   bool
+		notifications int
+	}
+	cases := map[string]struct {
+		rules    []ModerationRule
+		rulesErr error
+		wants
+	}{
+		"ok: published and notified": {
+			wants: wants{published: true, notifications: 1},
+		},
+		"ok: flagged draft is quarantined without being listed": {
+			rules: []ModerationRule{{Word: "jacket", Reason: "prohibited"}},
+			wants: wants{quarantined: true},
+		},
+		"ng: draft stays a draft when moderation fails": {
+			rulesErr: errors.New("database is locked"),
+			wants:    wants{},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockSSR := NewMockSavedSearchRepository(ctrl)
+			mockMR := NewMockModerationRepository(ctrl)
+			publishAt := time.Now().UTC().Add(-time.Minute)
+			item := Item{ID: 4, Name: "jacket", CategoryID: 2, Image: "a.jpg", SellerID: 1, Status: ItemStatusDraft, PublishAt: &publishAt}
+			mockIR.EXPECT().ListDue(gomock.Any(), gomock.Any()).Return([]Item{item}, nil)
+			mockMR.EXPECT().ListRules(gomock.Any(), 2).Return(tt.rules, tt.rulesErr)
+			if tt.wants.quarantined {
+				mockMR.EXPECT().Quarantine(gomock.Any(), 4, []string{"prohibited"}).Return(&ModerationReview{}, nil)
+			}
+			if tt.wants.published {
+				mockIR.EXPECT().Publish(gomock.Any(), 4).Return(nil)
+				mockIR.EXPECT().GetCategoryName(gomock.Any(), 2).Return("fashion", nil)
+				mockSSR.EXPECT().ListMatching(gomock.Any(), gomock.Any(), "fashion").Return([]SavedSearch{
+					{ID: 1, UserID: 5, SearchQuery: SearchQuery{Keyword: "jacket"}},
+				}, nil)
+			}
+
+			notifier := &recordingNotifier{}
+			h := &Handlers{itemRepo: mockIR, savedSearchRepo: mockSSR, moderationRepo: mockMR, notifier: notifier}
+
+			h.publishScheduledItems(context.Background())
+
+			if len(notifier.notifications) != tt.wants.notifications {
+				t.Fatalf("expected %d notifications, got %+v", tt.wants.notifications, notifier.notifications)
+			}
+			if tt.wants.notifications > 0 && notifier.notifications[0].UserID != 5 {
+				t.Errorf("unexpected notifications: %+v", notifier.notifications)
+			}
+		})
+	}
+}
+
+func TestItemRepositoryListDue(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	repo := &itemRepository{db: db}
+	ctx := context.Background()
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	for _, item := range []*Item{
+		{Name: "due", CategoryID: 1, Image: "a.jpg", Status: ItemStatusDraft, PublishAt: &past},
+		{Name: "later", CategoryID: 1, Image: "a.jpg", Status: ItemStatusDraft, PublishAt: &future},
+		{Name: "incomplete", CategoryID: 1, Status: ItemStatusDraft, PublishAt: &past},
+		{Name: "unscheduled", CategoryID: 1, Image: "a.jpg", Status: ItemStatusDraft},
+	} {
+		if err := repo.Insert(ctx, item); err != nil {
+			t.Fatalf("failed to insert item: %v", err)
+		}
+	}
+
+	due, err := repo.ListDue(ctx, now)
+	if err != nil {
+		t.Fatalf("failed to list due items: %v", err)
+	}
+	if len(due) != 1 || due[0].Name != "due" || due[0].Status != ItemStatusDraft {
+		t.Fatalf("unexpected due items: %+v", due)
+	}
+
+	// listing the due drafts doesn't publish them
+	items, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("failed to get items: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected no listed items, got %+v", items)
+	}
+}
+
+func TestDraftImageStoredLast(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		method string
+		fields map[string]string
+		// injector is used to inject the expected calls to the mock
+		injector func(m *MockItemRepository)
+		handler  func(h *Handlers) http.HandlerFunc
+	}{
+		"ng: invalid attribute on a new draft": {
+			method: "POST",
+			fields: map[string]string{"draft": "true", "category": "smartphones", "attr.color": "purple"},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetCategoryID(gomock.Any(), "smartphones").Return(2, nil)
+			},
+			handler: func(h *Handlers) http.HandlerFunc { return h.AddItem },
+		},
+		"ng: invalid attribute on an updated draft": {
+			method: "PATCH",
+			fields: map[string]string{"attr.color": "purple"},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, CategoryID: 2, SellerID: 1, Status: ItemStatusDraft}, nil)
+			},
+			handler: func(h *Handlers) http.HandlerFunc { return h.UpdateItem },
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			tt.injector(mockIR)
+			mockASR := NewMockAttributeSchemaRepository(ctrl)
+			mockASR.EXPECT().GetSchema(gomock.Any(), 2).Return(defaultAttributeSchemas["smartphones"], nil).AnyTimes()
+			dir := t.TempDir()
+			h := &Handlers{imgDirPath: dir, itemRepo: mockIR, attributeSchemaRepo: mockASR}
+
+			var b bytes.Buffer
+			w := multipart.NewWriter(&b)
+			for k, v := range tt.fields {
+				_ = w.WriteField(k, v)
+			}
+			fileWriter, err := w.CreateFormFile("image", "dummy.jpg")
+			if err != nil {
+				t.Fatalf("failed to create form file: %v", err)
+			}
+			if _, err := fileWriter.Write(testJPEG); err != nil {
+				t.Fatalf("failed to write dummy image data: %v", err)
+			}
+			w.Close()
+
+			req := httptest.NewRequest(tt.method, "/items/1", &b)
+			req.Header.Set("Content-Type", w.FormDataContentType())
+			req.Header.Set(userIDHeader, "1")
+			req.SetPathValue("item_id", "1")
+			rr := httptest.NewRecorder()
+			tt.handler(h)(rr, req)
+
+			if rr.Code != http.StatusUnprocessableEntity {
+				t.Errorf("expected status code %d, got %d: %s", http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+			}
+			// the rejected request leaves no image behind
+			stored, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("failed to read image directory: %v", err)
+			}
+			if len(stored) > 0 {
+				t.Errorf("expected no image stored, got %v", stored)
+			}
+		})
+	}
+}
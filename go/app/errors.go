@@ -0,0 +1,149 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// ErrorCode identifies the kind of an error, so that clients can branch on it
+// without parsing the message.
+type ErrorCode string
+
+// The codes of the errors without a more specific code, by status.
+const (
+	codeBadRequest   ErrorCode = "bad_request"
+	codeUnauthorized ErrorCode = "unauthorized"
+	codeForbidden    ErrorCode = "forbidden"
+	codeNotFound     ErrorCode = "not_found"
+	codeConflict     ErrorCode = "conflict"
+	codeRateLimited  ErrorCode = "rate_limited"
+	codeInternal     ErrorCode = "internal_error"
+)
+
+// codeValidationFailed is the code of a *ValidationError, with the invalid fields as the details.
+const codeValidationFailed ErrorCode = "validation_failed"
+
+// statusCodes are the codes of the errors created by newAPIError.
+var statusCodes = map[int]ErrorCode{
+	http.StatusBadRequest:          codeBadRequest,
+	http.StatusUnauthorized:        codeUnauthorized,
+	http.StatusForbidden:           codeForbidden,
+	http.StatusNotFound:            codeNotFound,
+	http.StatusConflict:            codeConflict,
+	http.StatusTooManyRequests:     codeRateLimited,
+	http.StatusInternalServerError: codeInternal,
+}
+
+// errorMappings are the statuses and the codes of the sentinel errors returned by the
+// repositories and the parsers. The first mapping matching with errors.Is is used.
+var errorMappings = []struct {
+	err    error
+	status int
+	code   ErrorCode
+}{
+	{errUserIDRequired, http.StatusUnauthorized, "user_id_required"},
+	{errInvalidUserID, http.StatusUnauthorized, "invalid_user_id"},
+	{errNotAdmin, http.StatusForbidden, "admin_required"},
+	{errItemNotFound, http.StatusNotFound, "item_not_found"},
+	{errImageNotFound, http.StatusNotFound, "image_not_found"},
+	{errItemNotOnSale, http.StatusConflict, "item_not_on_sale"},
+	{errItemNotDraft, http.StatusConflict, "item_not_draft"},
+	{errSellerNotFound, http.StatusNotFound, "seller_not_found"},
+	{errCommentNotFound, http.StatusNotFound, "comment_not_found"},
+	{errOfferNotFound, http.StatusNotFound, "offer_not_found"},
+	{errOfferNotOpen, http.StatusConflict, "offer_not_open"},
+	{errItemReserved, http.StatusConflict, "item_reserved"},
+	{errOrderNotFound, http.StatusNotFound, "order_not_found"},
+	{errOrderStatusConflict, http.StatusConflict, "order_status_conflict"},
+	{errConversationNotFound, http.StatusNotFound, "conversation_not_found"},
+	{errAlreadyRated, http.StatusConflict, "already_rated"},
+	{errSavedSearchNotFound, http.StatusNotFound, "saved_search_not_found"},
+	{errModerationRuleNotFound, http.StatusNotFound, "moderation_rule_not_found"},
+	{errModerationReviewNotFound, http.StatusNotFound, "moderation_review_not_found"},
+	{errReviewAlreadyResolved, http.StatusConflict, "review_already_resolved"},
+	{errAlreadyReported, http.StatusConflict, "already_reported"},
+	{errNoOpenReports, http.StatusConflict, "no_open_reports"},
+}
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	// Details has more information depending on the code.
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// APIError is an error shown to the client as it is.
+type APIError struct {
+	Status  int
+	Code    ErrorCode
+	Message string
+	Details any
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// newAPIError creates an error with the code of the status, such as bad_request for 400.
+func newAPIError(status int, message string) *APIError {
+	return &APIError{Status: status, Code: statusCodes[status], Message: message}
+}
+
+// toAPIError converts err to the error shown to the client. A mapped sentinel error keeps the
+// detail it is wrapped with as "%w: detail", but not the context of "failed to ...: %w".
+// Any other error is internal, and its message is hidden.
+func toAPIError(err error) (apiErr *APIError, internal bool) {
+	if errors.As(err, &apiErr) {
+		return apiErr, false
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return &APIError{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    codeValidationFailed,
+			Message: validationErr.Error(),
+			Details: validationErr.Fields,
+		}, false
+	}
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			message := m.err.Error()
+			if full := err.Error(); strings.HasPrefix(full, message) {
+				message = full
+			}
+			return &APIError{Status: m.status, Code: m.code, Message: message}, false
+		}
+	}
+	return newAPIError(http.StatusInternalServerError, "internal server error"), true
+}
+
+// writeError writes err as an ErrorResponse with the request ID. Internal errors are logged,
+// so handlers return them wrapped with what failed, such as "failed to get item: %w".
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr, internal := toAPIError(err)
+	if internal {
+		slog.ErrorContext(r.Context(), "internal error", "error", err, "method", r.Method, "path", r.URL.Path)
+	}
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	writeJSON(w, apiErr.Status, ErrorResponse{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Details:   apiErr.Details,
+		RequestID: requestIDFromContext(r.Context()),
+	})
+}
+
+// badRequest creates an error showing the message of err, such as a parse error, to the client.
+// A *ValidationError is kept as it is, so that it is written as 422 with every invalid field.
+func badRequest(err error) error {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return err
+	}
+	return newAPIError(http.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteError(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+		body ErrorResponse
+	}
+	cases := map[string]struct {
+		err error
+		wants
+	}{
+		"sentinel": {
+			err: fmt.Errorf("failed to get item: %w", errItemNotFound),
+			wants: wants{
+				code: http.StatusNotFound,
+				body: ErrorResponse{Code: "item_not_found", Message: "item not found", RequestID: "req-1"},
+			},
+		},
+		"sentinel with detail": {
+			err: fmt.Errorf("%w: order is already completed", errOrderStatusConflict),
+			wants: wants{
+				code: http.StatusConflict,
+				body: ErrorResponse{Code: "order_status_conflict", Message: "order status has changed: order is already completed", RequestID: "req-1"},
+			},
+		},
+		"api error": {
+			err: newAPIError(http.StatusBadRequest, "invalid item ID"),
+			wants: wants{
+				code: http.StatusBadRequest,
+				body: ErrorResponse{Code: codeBadRequest, Message: "invalid item ID", RequestID: "req-1"},
+			},
+		},
+		"validation error": {
+			err: newValidationError("price", "price must be a positive integer"),
+			wants: wants{
+				code: http.StatusUnprocessableEntity,
+				body: ErrorResponse{
+					Code:      codeValidationFailed,
+					Message:   "price must be a positive integer",
+					Details:   []any{map[string]any{"field": "price", "message": "price must be a positive integer"}},
+					RequestID: "req-1",
+				},
+			},
+		},
+		"internal error": {
+			err: fmt.Errorf("failed to get item: %w", errors.New("database is locked")),
+			wants: wants{
+				code: http.StatusInternalServerError,
+				body: ErrorResponse{Code: codeInternal, Message: "internal server error", RequestID: "req-1"},
+			},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "/items/1", nil)
+			req = req.WithContext(withRequestID(req.Context(), "req-1"))
+			rr := httptest.NewRecorder()
+			writeError(rr, req, tt.err)
+
+			if rr.Code != tt.wants.code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+			if got := rr.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("expected JSON, got %q", got)
+			}
+			var got ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if diff := cmp.Diff(tt.wants.body, got); diff != "" {
+				t.Errorf("unexpected response (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeFeedCursor encodes the cursor into an opaque string for clients.
+func encodeFeedCursor(c FeedCursor) string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixNano(), c.ItemID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor decodes a cursor made by encodeFeedCursor.
+func decodeFeedCursor(s string) (*FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, errors.New("invalid cursor")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	itemID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return &FeedCursor{CreatedAt: time.Unix(0, n).UTC(), ItemID: itemID}, nil
+}
+
+// parseSellerID parses the seller ID from the path.
+func parseSellerID(r *http.Request) (int, error) {
+	sellerID, err := strconv.Atoi(r.PathValue("seller_id"))
+	if err != nil || sellerID < 1 {
+		return 0, errors.New("invalid seller ID")
+	}
+	return sellerID, nil
+}
+
+// FollowSeller is a handler to follow a seller for POST /sellers/{seller_id}/follow .
+func (s *Handlers) FollowSeller(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	sellerID, err := parseSellerID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	if sellerID == userID {
+		writeError(w, r, newAPIError(http.StatusBadRequest, "users cannot follow themselves"))
+		return
+	}
+
+	if err := s.followRepo.Follow(r.Context(), userID, sellerID); err != nil {
+		writeError(w, r, fmt.Errorf("failed to follow seller: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnfollowSeller is a handler to stop following a seller for DELETE /sellers/{seller_id}/follow .
+func (s *Handlers) UnfollowSeller(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	sellerID, err := parseSellerID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	if err := s.followRepo.Unfollow(r.Context(), userID, sellerID); err != nil {
+		writeError(w, r, fmt.Errorf("failed to unfollow seller: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type GetFeedResponse struct {
+	Items []Item `json:"items"`
+	// NextCursor is passed as the cursor query parameter to get the next page, and is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// GetFeed is a handler to return the items newly listed by the followed sellers for GET /feed .
+// The feed is paged with the cursor only, since offsets would shift as the sellers list new items,
+// and a request with an offset is rejected.
+func (s *Handlers) GetFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if r.URL.Query().Has("offset") {
+		writeError(w, r, newValidationError("offset", "offset is not supported, use cursor"))
+		return
+	}
+	limit, _, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	var after *FeedCursor
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		after, err = decodeFeedCursor(v)
+		if err != nil {
+			writeError(w, r, badRequest(err))
+			return
+		}
+	}
+
+	// fetch one extra item to know whether there is a next page
+	items, err := s.followRepo.ListFeedItems(r.Context(), userID, after, limit+1)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get feed: %w", err))
+		return
+	}
+
+	resp := GetFeedResponse{Items: items}
+	if len(items) > limit {
+		resp.Items = items[:limit]
+		last := resp.Items[limit-1]
+		resp.NextCursor = encodeFeedCursor(FeedCursor{CreatedAt: last.CreatedAt, ItemID: last.ID})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
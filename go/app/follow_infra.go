@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// FeedCursor points at the last item of a feed page.
+// The next page starts right after it in reverse-chronological order.
+type FeedCursor struct {
+	CreatedAt time.Time
+	ItemID    int
+}
+
+// FollowRepository is an interface to manage the sellers users follow and their feeds.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type FollowRepository interface {
+	// Follow makes the user follow the seller. Following twice is not an error.
+	Follow(ctx context.Context, followerID, sellerID int) error
+	// Unfollow makes the user stop following the seller. Unfollowing a seller not followed is not an error.
+	Unfollow(ctx context.Context, followerID, sellerID int) error
+	// ListFeedItems returns the items listed by the sellers the user follows, newest first,
+	// starting after the cursor if it is not nil.
+	ListFeedItems(ctx context.Context, followerID int, after *FeedCursor, limit int) ([]Item, error)
+}
+
+// followRepository is an implementation of FollowRepository
+type followRepository struct {
+	db *sql.DB
+}
+
+// NewFollowRepository creates a new followRepository.
+func NewFollowRepository(database *sql.DB) FollowRepository {
+	return &followRepository{db: database}
+}
+
+func (f *followRepository) Follow(ctx context.Context, followerID, sellerID int) error {
+	_, err := f.db.ExecContext(ctx, "INSERT OR IGNORE INTO follows (follower_id, seller_id, created_at) VALUES (?, ?, ?)",
+		followerID, sellerID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to follow seller: %w", err)
+	}
+	return nil
+}
+
+func (f *followRepository) Unfollow(ctx context.Context, followerID, sellerID int) error {
+	_, err := f.db.ExecContext(ctx, "DELETE FROM follows WHERE follower_id = ? AND seller_id = ?", followerID, sellerID)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow seller: %w", err)
+	}
+	return nil
+}
+
+func (f *followRepository) ListFeedItems(ctx context.Context, followerID int, after *FeedCursor, limit int) ([]Item, error) {
+	query := "SELECT " + itemColumns + `
+		FROM items
+		JOIN follows ON follows.seller_id = items.seller_id
+		WHERE follows.follower_id = ? AND ` + listedItemsCondition
+	args := []any{followerID}
+	if after != nil {
+		query += " AND (items.created_at < ? OR (items.created_at = ? AND items.id < ?))"
+		args = append(args, after.CreatedAt, after.CreatedAt, after.ItemID)
+	}
+	query += " ORDER BY items.created_at DESC, items.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		if err := scanItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate feed: %w", err)
+	}
+
+	return items, nil
+}
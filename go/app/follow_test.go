@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFeedCursor(t *testing.T) {
+	t.Parallel()
+
+	want := FeedCursor{CreatedAt: time.Date(2025, 4, 1, 12, 30, 0, 123456789, time.UTC), ItemID: 42}
+	got, err := decodeFeedCursor(encodeFeedCursor(want))
+	if err != nil {
+		t.Fatalf("failed to decode cursor: %v", err)
+	}
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("unexpected cursor (-want +got):\n%s", diff)
+	}
+
+	if _, err := decodeFeedCursor("not a cursor"); err == nil {
+		t.Errorf("expected an error for a broken cursor")
+	}
+}
+
+func TestGetFeed(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: 3, SellerID: 1, CreatedAt: now},
+		{ID: 2, SellerID: 1, CreatedAt: now.Add(-time.Minute)},
+		{ID: 1, SellerID: 4, CreatedAt: now.Add(-time.Hour)},
+	}
+
+	type wants struct {
+		code       int
+		itemIDs    []int
+		nextCursor *FeedCursor
+	}
+	cases := map[string]struct {
+		query    string
+		injector func(m *MockFollowRepository)
+		wants
+	}{
+		"ok: first page with a next page": {
+			query: "?limit=2",
+			injector: func(m *MockFollowRepository) {
+				m.EXPECT().ListFeedItems(gomock.Any(), 9, nil, 3).Return(items, nil).Times(1)
+			},
+			wants: wants{
+				code:       http.StatusOK,
+				itemIDs:    []int{3, 2},
+				nextCursor: &FeedCursor{CreatedAt: items[1].CreatedAt, ItemID: 2},
+			},
+		},
+		"ok: last page": {
+			query: "?limit=2&cursor=" + encodeFeedCursor(FeedCursor{CreatedAt: items[1].CreatedAt, ItemID: 2}),
+			injector: func(m *MockFollowRepository) {
+				m.EXPECT().ListFeedItems(gomock.Any(), 9, &FeedCursor{CreatedAt: items[1].CreatedAt, ItemID: 2}, 3).
+					Return(items[2:], nil).Times(1)
+			},
+			wants: wants{
+				code:    http.StatusOK,
+				itemIDs: []int{1},
+			},
+		},
+		"ng: offset": {
+			query:    "?offset=2",
+			injector: func(m *MockFollowRepository) {},
+			wants:    wants{code: http.StatusUnprocessableEntity},
+		},
+		"ng: broken cursor": {
+			query:    "?cursor=bm9wZQ",
+			injector: func(m *MockFollowRepository) {},
+			wants:    wants{code: http.StatusBadRequest},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockFR := NewMockFollowRepository(ctrl)
+			tt.injector(mockFR)
+			h := &Handlers{followRepo: mockFR}
+
+			req := httptest.NewRequest("GET", "/feed"+tt.query, nil)
+			req.Header.Set(userIDHeader, "9")
+
+			rr := httptest.NewRecorder()
+			h.GetFeed(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Fatalf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+			if tt.wants.code >= 400 {
+				return
+			}
+
+			var got GetFeedResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response body: %v", err)
+			}
+			var gotIDs []int
+			for _, item := range got.Items {
+				gotIDs = append(gotIDs, item.ID)
+			}
+			if diff := cmp.Diff(tt.wants.itemIDs, gotIDs); diff != "" {
+				t.Errorf("unexpected items (-want +got):\n%s", diff)
+			}
+
+			var gotCursor *FeedCursor
+			if got.NextCursor != "" {
+				c, err := decodeFeedCursor(got.NextCursor)
+				if err != nil {
+					t.Fatalf("failed to decode next cursor: %v", err)
+				}
+				gotCursor = c
+			}
+			if diff := cmp.Diff(tt.wants.nextCursor, gotCursor); diff != "" {
+				t.Errorf("unexpected next cursor (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFollowRepositoryListFeedItems(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	ctx := context.Background()
+	itemRepo := &itemRepository{db: db}
+	followRepo := NewFollowRepository(db)
+	var ids []int
+	for range 3 {
+		item := &Item{Name: "jacket", CategoryID: 1, Image: "a.jpg", SellerID: 1, Status: ItemStatusOnSale}
+		if err := itemRepo.Insert(ctx, item); err != nil {
+			t.Fatalf("failed to insert item: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+	// the items listed before created_at was added are dated to the epoch without a time zone
+	if _, err := db.Exec("UPDATE items SET created_at = '1970-01-01 00:00:00' WHERE id IN (?, ?)", ids[0], ids[1]); err != nil {
+		t.Fatalf("failed to date items to the epoch: %v", err)
+	}
+	// and are normalized at startup
+	if err := normalizeItemsCreatedAt(db); err != nil {
+		t.Fatalf("failed to normalize creation times: %v", err)
+	}
+	if err := followRepo.Follow(ctx, 9, 1); err != nil {
+		t.Fatalf("failed to follow seller: %v", err)
+	}
+
+	var got []int
+	var after *FeedCursor
+	for range 5 {
+		items, err := followRepo.ListFeedItems(ctx, 9, after, 1)
+		if err != nil {
+			t.Fatalf("failed to list feed items: %v", err)
+		}
+		if len(items) == 0 {
+			break
+		}
+		got = append(got, items[0].ID)
+		after = &FeedCursor{CreatedAt: items[0].CreatedAt, ItemID: items[0].ID}
+	}
+	// newest first, each item once
+	if diff := cmp.Diff([]int{ids[2], ids[1], ids[0]}, got); diff != "" {
+		t.Errorf("unexpected feed pages (-want +got):\n%s", diff)
+	}
+}
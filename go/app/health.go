@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// readinessTimeout bounds the checks of a readiness probe.
+const readinessTimeout = 2 * time.Second
+
+// HealthResponse is the result of a probe. Failed has the names of the checks that failed;
+// their errors are only logged, since the probe is served to anyone.
+type HealthResponse struct {
+	Status string   `json:"status"`
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Healthz is a handler to tell that the process is alive for GET /healthz .
+func (s *Handlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz is a handler to tell whether the server can serve requests for GET /readyz :
+// the database answers, its schema is complete and the image directory is writable.
+func (s *Handlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	var failed []string
+	check := func(name string, err error) {
+		if err != nil {
+			slog.WarnContext(ctx, "readiness check failed", "check", name, "error", err)
+			failed = append(failed, name)
+		}
+	}
+
+	dbErr := s.healthRepo.Ping(ctx)
+	check("database", dbErr)
+	if dbErr == nil {
+		check("schema", s.checkSchema(ctx))
+	}
+	check("images", checkWritableDir(s.imgDirPath))
+
+	if len(failed) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Failed: failed})
+		return
+	}
+	writeJSON(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// checkSchema fails if InitDB has not created all the tables and columns.
+func (s *Handlers) checkSchema(ctx context.Context) error {
+	missing, err := s.healthRepo.MissingSchema(ctx)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkWritableDir fails if a file cannot be created in the directory.
+func checkWritableDir(dir string) error {
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("directory is not writable: %w", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// VersionResponse is the build information of the server.
+type VersionResponse struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	Revision  string `json:"revision,omitempty"`
+	// Time is the commit time of the revision.
+	Time     string `json:"time,omitempty"`
+	Modified bool   `json:"modified,omitempty"`
+}
+
+// buildVersion reads the build information embedded in the binary once.
+var buildVersion = sync.OnceValue(func() VersionResponse {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return VersionResponse{Version: "unknown"}
+	}
+
+	v := VersionResponse{Version: info.Main.Version, GoVersion: info.GoVersion}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			v.Revision = setting.Value
+		case "vcs.time":
+			v.Time = setting.Value
+		case "vcs.modified":
+			v.Modified = setting.Value == "true"
+		}
+	}
+	// tests and go run have no module version
+	if v.Version == "" {
+		v.Version = "(devel)"
+	}
+	return v
+})
+
+// Version is a handler to return the build information for GET /version .
+func (s *Handlers) Version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, buildVersion())
+}
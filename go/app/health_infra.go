@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// HealthRepository is an interface to check the state of the database.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	// MissingSchema returns the tables and columns created by InitDB that are not in the database,
+	// such as "reports" or "items.publish_at".
+	MissingSchema(ctx context.Context) ([]string, error)
+}
+
+// healthRepository is an implementation of HealthRepository
+type healthRepository struct {
+	db *sql.DB
+}
+
+// NewHealthRepository creates a new healthRepository.
+func NewHealthRepository(database *sql.DB) HealthRepository {
+	return &healthRepository{db: database}
+}
+
+func (h *healthRepository) Ping(ctx context.Context) error {
+	if err := h.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+func (h *healthRepository) MissingSchema(ctx context.Context) ([]string, error) {
+	tables, err := h.names(ctx, "SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	columns, err := h.names(ctx, "SELECT name FROM pragma_table_info('items')")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns of items: %w", err)
+	}
+
+	missing := []string{}
+	for _, t := range schemaTables {
+		if !tables[t.name] {
+			missing = append(missing, t.name)
+		}
+	}
+	for _, c := range itemsAddedColumns {
+		if !columns[c.name] {
+			missing = append(missing, "items."+c.name)
+		}
+	}
+	return missing, nil
+}
+
+// names returns the set of the names selected by the query.
+func (h *healthRepository) names(ctx context.Context, query string) (map[string]bool, error) {
+	rows, err := h.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[name] = true
+	}
+	return names, rows.Err()
+}
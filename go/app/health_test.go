@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReadyz(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code   int
+		failed []string
+	}
+	cases := map[string]struct {
+		missingDir bool
+		// injector is used to inject the expected calls to the mock
+		injector func(m *MockHealthRepository)
+		wants
+	}{
+		"ok: ready": {
+			injector: func(m *MockHealthRepository) {
+				m.EXPECT().Ping(gomock.Any()).Return(nil)
+				m.EXPECT().MissingSchema(gomock.Any()).Return([]string{}, nil)
+			},
+			wants: wants{code: http.StatusOK},
+		},
+		"ng: database down": {
+			injector: func(m *MockHealthRepository) {
+				m.EXPECT().Ping(gomock.Any()).Return(errors.New("disk I/O error"))
+			},
+			wants: wants{code: http.StatusServiceUnavailable, failed: []string{"database"}},
+		},
+		"ng: migrations not applied": {
+			injector: func(m *MockHealthRepository) {
+				m.EXPECT().Ping(gomock.Any()).Return(nil)
+				m.EXPECT().MissingSchema(gomock.Any()).Return([]string{"reports", "items.publish_at"}, nil)
+			},
+			wants: wants{code: http.StatusServiceUnavailable, failed: []string{"schema"}},
+		},
+		"ng: image directory not writable": {
+			missingDir: true,
+			injector: func(m *MockHealthRepository) {
+				m.EXPECT().Ping(gomock.Any()).Return(nil)
+				m.EXPECT().MissingSchema(gomock.Any()).Return([]string{}, nil)
+			},
+			wants: wants{code: http.StatusServiceUnavailable, failed: []string{"images"}},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockHR := NewMockHealthRepository(ctrl)
+			tt.injector(mockHR)
+			dir := t.TempDir()
+			if tt.missingDir {
+				dir = filepath.Join(dir, "missing")
+			}
+			h := &Handlers{healthRepo: mockHR, imgDirPath: dir}
+
+			req := httptest.NewRequest("GET", "/readyz", nil)
+			rr := httptest.NewRecorder()
+			h.Readyz(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Fatalf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+			var resp HealthResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			// the errors are logged, not returned
+			if diff := cmp.Diff(tt.wants.failed, resp.Failed); diff != "" {
+				t.Errorf("unexpected failed checks (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+	(&Handlers{}).Version(rr, httptest.NewRequest("GET", "/version", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	var resp VersionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Version == "" || resp.GoVersion == "" {
+		t.Errorf("expected the version and the Go version, got %+v", resp)
+	}
+}
+
+func TestHealthRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, err := InitDB(filepath.Join(t.TempDir(), "mercari.sqlite3"))
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := NewHealthRepository(db)
+	ctx := context.Background()
+
+	if err := repo.Ping(ctx); err != nil {
+		t.Fatalf("failed to ping: %v", err)
+	}
+	missing, err := repo.MissingSchema(ctx)
+	if err != nil {
+		t.Fatalf("failed to check schema: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("expected a complete schema, missing %v", missing)
+	}
+
+	if _, err := db.Exec("DROP TABLE reports"); err != nil {
+		t.Fatalf("failed to drop table: %v", err)
+	}
+	missing, err = repo.MissingSchema(ctx)
+	if err != nil {
+		t.Fatalf("failed to check schema: %v", err)
+	}
+	if diff := cmp.Diff([]string{"reports"}, missing); diff != "" {
+		t.Errorf("unexpected missing schema (-want +got):\n%s", diff)
+	}
+}
@@ -5,18 +5,113 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"os"
+	"slices"
+	"strings"
+	"time"
+
 	// STEP 5-1: uncomment this line
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName is the sqlite3 driver with the functions the queries use on every connection,
+// since the driver is built without the math functions of SQLite.
+const sqliteDriverName = "sqlite3_app"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("pow", math.Pow, true)
+		},
+	})
+}
+
+var (
+	errImageNotFound  = errors.New("image not found")
+	errItemNotFound   = errors.New("item not found")
+	errItemNotOnSale  = errors.New("item is not on sale")
+	errItemNotDraft   = errors.New("item is not a draft")
+	errSellerNotFound = errors.New("seller not found")
+)
+
+// ItemStatus is the sale status of an item.
+type ItemStatus string
+
+const (
+	ItemStatusOnSale  ItemStatus = "on_sale"
+	ItemStatusSoldOut ItemStatus = "sold_out"
+	// ItemStatusDraft is an item prepared by the seller and not listed yet.
+	ItemStatusDraft ItemStatus = "draft"
+	// ItemStatusQuarantined is an item flagged by moderation and waiting for a review.
+	ItemStatusQuarantined ItemStatus = "quarantined"
+	// ItemStatusRejected is an item rejected by a review.
+	ItemStatusRejected ItemStatus = "rejected"
+	// ItemStatusHidden is an item reported by many users and waiting for a moderator.
+	ItemStatusHidden ItemStatus = "hidden"
 )
 
-var errImageNotFound = errors.New("image not found")
+// listed reports whether the items with the status are shown to everyone.
+func (s ItemStatus) listed() bool {
+	return s == ItemStatusOnSale || s == ItemStatusSoldOut
+}
 
 type Item struct {
-	ID         int    `db:"id" json:"-"`
-	Name       string `db:"name" json:"name"`
-	CategoryID int    `db:"category_id" json:"category_id"`
-	Image      string `db:"image_name" json:"image_name"`
+	ID           int        `db:"id" json:"id"`
+	Name         string     `db:"name" json:"name"`
+	CategoryID   int        `db:"category_id" json:"category_id"`
+	Image        string     `db:"image_name" json:"image_name"`
+	SellerID     int        `db:"seller_id" json:"seller_id"`
+	Price        int        `db:"price" json:"price"`
+	Status       ItemStatus `db:"status" json:"status"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	CommentCount int        `db:"comment_count" json:"comment_count"`
+	// PublishAt is the time a draft is published at. It is nil unless publishing is scheduled.
+	PublishAt *time.Time `db:"publish_at" json:"publish_at,omitempty"`
+	// Attributes are the values of the attributes defined by the category, by attribute name.
+	Attributes map[string]string `db:"-" json:"attributes,omitempty"`
+}
+
+// missingFields returns the fields required to list the item that are not set yet.
+// Drafts may be saved without them, but cannot be published until they are filled in.
+func (item *Item) missingFields() []string {
+	var missing []string
+	if item.Name == "" {
+		missing = append(missing, "name")
+	}
+	if item.CategoryID == 0 {
+		missing = append(missing, "category")
+	}
+	if item.Image == "" {
+		missing = append(missing, "image")
+	}
+	return missing
+}
+
+// visibleTo reports whether the user can see the item.
+// Drafts and items held by moderation are visible only to their seller.
+func (item *Item) visibleTo(userID int) bool {
+	return item.Status.listed() || item.SellerID == userID
+}
+
+// PriceChange is an entry of the price history of an item.
+type PriceChange struct {
+	ItemID    int       `db:"item_id" json:"item_id"`
+	OldPrice  int       `db:"old_price" json:"old_price"`
+	NewPrice  int       `db:"new_price" json:"new_price"`
+	ChangedAt time.Time `db:"changed_at" json:"changed_at"`
+}
+
+// SearchQuery is the keyword and filters to search items with.
+// Zero values mean the filter is not set.
+type SearchQuery struct {
+	Keyword  string `json:"keyword"`
+	Category string `json:"category,omitempty"`
+	MinPrice int    `json:"min_price,omitempty"`
+	MaxPrice int    `json:"max_price,omitempty"`
+	// Attributes filter the items having all of these attribute values.
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 type ItemName struct {
@@ -32,39 +127,166 @@ type ItemName struct {
 //go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
 type ItemRepository interface {
 	Insert(ctx context.Context, item *Item) error
+	// InsertQuarantined inserts an item flagged by the moderation rules as quarantined,
+	// with a pending review of the reasons, in one transaction.
+	InsertQuarantined(ctx context.Context, item *Item, reasons []string) (*ModerationReview, error)
 	GetAll(ctx context.Context) ([]Item, error)
 	GetByID(ctx context.Context, itemID int) (*Item, error)
 	GetCategoryID(ctx context.Context, categoryName string) (int, error)
-	SearchByKeyword(ctx context.Context, keyword string) (*sql.Rows, error)
+	Search(ctx context.Context, query *SearchQuery) (*sql.Rows, error)
+	// Update updates the editable fields and the attributes of the item,
+	// recording the price history when the price of a listed item changes.
+	Update(ctx context.Context, item *Item) error
+	// UpdateQuarantined updates a listed item flagged by the moderation rules as Update does,
+	// and quarantines it with a pending review of the reasons in the same transaction.
+	UpdateQuarantined(ctx context.Context, item *Item, reasons []string) (*ModerationReview, error)
+	GetPriceHistory(ctx context.Context, itemID int) ([]PriceChange, error)
+	GetCategoryName(ctx context.Context, categoryID int) (string, error)
+	// Publish lists a draft now. It returns errItemNotDraft if the item is not a draft.
+	Publish(ctx context.Context, itemID int) error
+	// ListDue returns the complete drafts whose publish_at is not after now, without publishing them,
+	// so that they can be moderated before being listed.
+	ListDue(ctx context.Context, now time.Time) ([]Item, error)
+	// ListDrafts returns the drafts of the seller, most recently created first.
+	ListDrafts(ctx context.Context, sellerID int) ([]Item, error)
+	// HasListedItems reports whether the seller has listed any item. There are no user records,
+	// so a seller is known by their listings.
+	HasListedItems(ctx context.Context, sellerID int) (bool, error)
+	// ListComparable returns the listed and sold items of the category with a price whose name contains
+	// any of the keywords, most recently created first.
+	ListComparable(ctx context.Context, category string, keywords []string, limit int) ([]Item, error)
+	// ListByIDs returns the items with the IDs in no particular order, skipping the missing ones.
+	ListByIDs(ctx context.Context, itemIDs []int) ([]Item, error)
 }
 
 // itemRepository is an implementation of ItemRepository
 type itemRepository struct {
 	db *sql.DB
+	// index is refreshed as items are stored, if it is not nil.
+	index *SimilarityIndex
 }
 
-// NewItemRepository creates a new itemRepository.
-func NewItemRepository(database *sql.DB) ItemRepository {
-	return &itemRepository{db: database}
+// NewItemRepository creates a new itemRepository keeping the index up to date.
+func NewItemRepository(database *sql.DB, index *SimilarityIndex) ItemRepository {
+	return &itemRepository{db: database, index: index}
 }
 
 // Insert inserts an item into the repository.
 func (i *itemRepository) Insert(ctx context.Context, item *Item) error {
+	_, err := i.insert(ctx, item, nil)
+	return err
+}
+
+func (i *itemRepository) InsertQuarantined(ctx context.Context, item *Item, reasons []string) (*ModerationReview, error) {
+	item.Status = ItemStatusQuarantined
+	return i.insert(ctx, item, reasons)
+}
+
+// insert inserts the item with its attributes, and the moderation review of the reasons if there are any.
+func (i *itemRepository) insert(ctx context.Context, item *Item, reasons []string) (*ModerationReview, error) {
 
 	// STEP 5-1: add an implementation to store an item
-	_, err := i.db.ExecContext(ctx, "INSERT INTO items (name, category_id, image_name) VALUES (?, ?, ?)", item.Name, item.CategoryID, item.Image)
+	if item.Status == "" {
+		item.Status = ItemStatusOnSale
+	}
+	item.CreatedAt = time.Now().UTC()
+
+	tx, err := i.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to insert item :%w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO items (name, category_id, image_name, seller_id, price, status, created_at, publish_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		item.Name, item.CategoryID, item.Image, item.SellerID, item.Price, item.Status, item.CreatedAt, item.PublishAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert item :%w", err)
 
 	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item id: %w", err)
+	}
+	if err := setItemAttributes(ctx, tx, int(id), item.Attributes); err != nil {
+		return nil, err
+	}
+	var review *ModerationReview
+	if len(reasons) > 0 {
+		if review, err = insertModerationReview(ctx, tx, int(id), reasons); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	item.ID = int(id)
+	i.index.Sync(item)
+	return review, nil
+}
+
+// setItemAttributes replaces the attributes of the item in the transaction.
+func setItemAttributes(ctx context.Context, tx *sql.Tx, itemID int, attrs map[string]string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM item_attributes WHERE item_id = ?", itemID); err != nil {
+		return fmt.Errorf("failed to delete item attributes: %w", err)
+	}
+	for name, value := range attrs {
+		_, err := tx.ExecContext(ctx, "INSERT INTO item_attributes (item_id, name, value) VALUES (?, ?, ?)", itemID, name, value)
+		if err != nil {
+			return fmt.Errorf("failed to insert item attribute: %w", err)
+		}
+	}
+	return nil
+}
+
+// getItemAttributes returns the attributes of the item, or nil if it has none.
+func (i *itemRepository) getItemAttributes(ctx context.Context, itemID int) (map[string]string, error) {
+	rows, err := i.db.QueryContext(ctx, "SELECT name, value FROM item_attributes WHERE item_id = ?", itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item attributes: %w", err)
+	}
+	defer rows.Close()
+
+	var attrs map[string]string
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan item attribute: %w", err)
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		attrs[name] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate item attributes: %w", err)
+	}
+	return attrs, nil
+}
+
+// itemColumns is the column list shared by the queries returning Item.
+const itemColumns = `items.id, items.name, items.category_id, items.image_name, items.seller_id, items.price, items.status, items.created_at,
+	(SELECT COUNT(*) FROM comments WHERE comments.item_id = items.id) AS comment_count, items.publish_at`
+
+// scanItem scans a row selected with itemColumns into an Item.
+func scanItem(row interface{ Scan(dest ...any) error }, item *Item) error {
+	var publishAt sql.NullTime
+	err := row.Scan(&item.ID, &item.Name, &item.CategoryID, &item.Image, &item.SellerID, &item.Price, &item.Status, &item.CreatedAt, &item.CommentCount, &publishAt)
+	if err != nil {
+		return err
+	}
+	item.PublishAt = nil
+	if publishAt.Valid {
+		item.PublishAt = &publishAt.Time
+	}
 	return nil
 }
 
+// listedItemsCondition excludes the items only their seller can see, such as drafts.
+const listedItemsCondition = "items.status IN ('" + string(ItemStatusOnSale) + "', '" + string(ItemStatusSoldOut) + "')"
+
 func (i *itemRepository) GetAll(ctx context.Context) ([]Item, error) {
-	rows, err := i.db.QueryContext(ctx, `
-		SELECT items.id, items.name, categories.categoryname AS category, items.image_name FROM items
-		JOIN categories ON items.category_id = categories.id
-	`)
+	rows, err := i.db.QueryContext(ctx, "SELECT "+itemColumns+" FROM items WHERE "+listedItemsCondition)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get items: %w", err)
 	}
@@ -73,10 +295,7 @@ func (i *itemRepository) GetAll(ctx context.Context) ([]Item, error) {
 	var items []Item
 	for rows.Next() {
 		var item Item
-		if err := rows.Scan(ctx, `SELECT items.id, items.name, categories.name 
-			FROM items 
-			JOIN categories ON items.category_id = categories.id;
-			`); err != nil {
+		if err := scanItem(rows, &item); err != nil {
 			return nil, fmt.Errorf("failed to scan item: %w", err)
 		}
 		items = append(items, item)
@@ -90,23 +309,301 @@ func (i *itemRepository) GetAll(ctx context.Context) ([]Item, error) {
 	return items, nil
 }
 
+func (i *itemRepository) ListDrafts(ctx context.Context, sellerID int) ([]Item, error) {
+	rows, err := i.db.QueryContext(ctx, "SELECT "+itemColumns+" FROM items WHERE items.seller_id = ? AND items.status = ? ORDER BY items.id DESC",
+		sellerID, ItemStatusDraft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get drafts: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		if err := scanItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate drafts: %w", err)
+	}
+
+	return items, nil
+}
+
+func (i *itemRepository) HasListedItems(ctx context.Context, sellerID int) (bool, error) {
+	var exists bool
+	err := i.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM items WHERE items.seller_id = ? AND "+listedItemsCondition+")", sellerID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check items of seller: %w", err)
+	}
+	return exists, nil
+}
+
+func (i *itemRepository) ListComparable(ctx context.Context, category string, keywords []string, limit int) ([]Item, error) {
+	if len(keywords) == 0 {
+		return []Item{}, nil
+	}
+	// the same keyword match as Search, with the keywords ORed
+	conditions := make([]string, len(keywords))
+	args := []any{category}
+	for k, keyword := range keywords {
+		conditions[k] = "items.name LIKE ?"
+		args = append(args, "%"+keyword+"%")
+	}
+	args = append(args, limit)
+
+	rows, err := i.db.QueryContext(ctx, "SELECT "+itemColumns+`
+		FROM items
+		JOIN categories ON items.category_id = categories.id
+		WHERE categories.name = ? AND `+listedItemsCondition+` AND items.price > 0
+			AND (`+strings.Join(conditions, " OR ")+`)
+		ORDER BY items.created_at DESC, items.id DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comparable items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		if err := scanItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate comparable items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (i *itemRepository) ListByIDs(ctx context.Context, itemIDs []int) ([]Item, error) {
+	if len(itemIDs) == 0 {
+		return []Item{}, nil
+	}
+	args := make([]any, len(itemIDs))
+	for k, id := range itemIDs {
+		args[k] = id
+	}
+
+	rows, err := i.db.QueryContext(ctx, "SELECT "+itemColumns+" FROM items WHERE items.id IN (?"+strings.Repeat(", ?", len(itemIDs)-1)+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		if err := scanItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate items: %w", err)
+	}
+
+	return items, nil
+}
+
 func (i *itemRepository) GetByID(ctx context.Context, itemID int) (*Item, error) {
 	var item Item
-	err := i.db.QueryRowContext(ctx, "SELECT id, name, category, image FROM items WHERE id = ?", itemID).
-		Scan(`SELECT items.id, items.name, categories.name 
-			FROM items 
-			JOIN categories ON items.category_id = categories.id;	
-		`)
+	err := scanItem(i.db.QueryRowContext(ctx, "SELECT "+itemColumns+" FROM items WHERE items.id = ?", itemID), &item)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errItemNotFound
+		}
+		return nil, fmt.Errorf("failed to query item: %w", err)
+	}
+	item.Attributes, err = i.getItemAttributes(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (i *itemRepository) Update(ctx context.Context, item *Item) error {
+	_, err := i.update(ctx, item, nil)
+	return err
+}
 
+func (i *itemRepository) UpdateQuarantined(ctx context.Context, item *Item, reasons []string) (*ModerationReview, error) {
+	review, err := i.update(ctx, item, reasons)
 	if err != nil {
+		return nil, err
+	}
+	item.Status = ItemStatusQuarantined
+	return review, nil
+}
+
+// update updates the item with its attributes, and quarantines it with a moderation review
+// of the reasons if there are any.
+func (i *itemRepository) update(ctx context.Context, item *Item, reasons []string) (*ModerationReview, error) {
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		oldPrice int
+		status   ItemStatus
+	)
+	err = tx.QueryRowContext(ctx, "SELECT price, status FROM items WHERE id = ?", item.ID).Scan(&oldPrice, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errItemNotFound
+		}
+		return nil, fmt.Errorf("failed to query item: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE items SET name = ?, category_id = ?, image_name = ?, price = ?, publish_at = ? WHERE id = ?",
+		item.Name, item.CategoryID, item.Image, item.Price, item.PublishAt, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update item: %w", err)
+	}
+	if err := setItemAttributes(ctx, tx, item.ID, item.Attributes); err != nil {
+		return nil, err
+	}
+
+	// nobody has seen the price of a draft, so its changes are not history
+	if oldPrice != item.Price && status != ItemStatusDraft {
+		_, err = tx.ExecContext(ctx, "INSERT INTO price_history (item_id, old_price, new_price, changed_at) VALUES (?, ?, ?, ?)",
+			item.ID, oldPrice, item.Price, time.Now().UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert price history: %w", err)
+		}
+	}
+
+	var review *ModerationReview
+	if len(reasons) > 0 {
+		_, err = tx.ExecContext(ctx, "UPDATE items SET status = ? WHERE id = ?", ItemStatusQuarantined, item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to quarantine item: %w", err)
+		}
+		if review, err = insertModerationReview(ctx, tx, item.ID, reasons); err != nil {
+			return nil, err
+		}
+		status = ItemStatusQuarantined
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	// the status is not editable, so the item is indexed with the stored one
+	indexed := *item
+	indexed.Status = status
+	i.index.Sync(&indexed)
+	return review, nil
+}
+
+// GetPriceHistory returns the price changes of the item, oldest first.
+func (i *itemRepository) GetPriceHistory(ctx context.Context, itemID int) ([]PriceChange, error) {
+	rows, err := i.db.QueryContext(ctx, "SELECT item_id, old_price, new_price, changed_at FROM price_history WHERE item_id = ? ORDER BY id", itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []PriceChange{}
+	for rows.Next() {
+		var c PriceChange
+		if err := rows.Scan(&c.ItemID, &c.OldPrice, &c.NewPrice, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price history: %w", err)
+		}
+		history = append(history, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate price history: %w", err)
+	}
+
+	return history, nil
+}
+
+func (i *itemRepository) GetCategoryName(ctx context.Context, categoryID int) (string, error) {
+	var name string
+	err := i.db.QueryRowContext(ctx, "SELECT name FROM categories WHERE id = ?", categoryID).Scan(&name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get category name: %w", err)
+	}
+	return name, nil
+}
+
+// Publish lists a draft now. The listing time becomes the creation time so that
+// the item shows up as new in the feeds.
+func (i *itemRepository) Publish(ctx context.Context, itemID int) error {
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE items SET status = ?, publish_at = NULL, created_at = ? WHERE id = ? AND status = ?",
+		ItemStatusOnSale, time.Now().UTC(), itemID, ItemStatusDraft)
+	if err != nil {
+		return fmt.Errorf("failed to publish item: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return errItemNotDraft
+	}
+	item, err := getItemTx(ctx, tx, itemID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	i.index.Sync(item)
+	return nil
+}
+
+// getItemTx loads the item within the transaction changing it, so that it can be indexed as committed.
+func getItemTx(ctx context.Context, tx *sql.Tx, itemID int) (*Item, error) {
+	var item Item
+	if err := scanItem(tx.QueryRowContext(ctx, "SELECT "+itemColumns+" FROM items WHERE id = ?", itemID), &item); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("item not found")
+			return nil, errItemNotFound
 		}
 		return nil, fmt.Errorf("failed to query item: %w", err)
 	}
 	return &item, nil
 }
 
+func (i *itemRepository) ListDue(ctx context.Context, now time.Time) ([]Item, error) {
+	rows, err := i.db.QueryContext(ctx, "SELECT "+itemColumns+`
+		FROM items
+		WHERE items.status = ? AND items.publish_at <= ?
+			AND items.name != '' AND items.category_id != 0 AND items.image_name != ''
+		ORDER BY items.publish_at, items.id`, ItemStatusDraft, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		var item Item
+		if err := scanItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate scheduled items: %w", err)
+	}
+	return items, nil
+}
+
 // get the category_id based on category
 func (i *itemRepository) GetCategoryID(ctx context.Context, categoryName string) (int, error) {
 	var categoryID int
@@ -129,12 +626,32 @@ func (i *itemRepository) GetCategoryID(ctx context.Context, categoryName string)
 	return categoryID, nil
 }
 
-func (i *itemRepository) SearchByKeyword(ctx context.Context, keyword string) (*sql.Rows, error) {
-	rows, err := i.db.QueryContext(ctx, `
+func (i *itemRepository) Search(ctx context.Context, query *SearchQuery) (*sql.Rows, error) {
+	q := `
 	SELECT items.id, items.name, categories.name, items.image_name
 	FROM items
 	JOIN categories ON items.category_id = categories.id
-	WHERE items.name LIKE ?`, "%"+keyword+"%")
+	WHERE items.name LIKE ? AND ` + listedItemsCondition
+	args := []any{"%" + query.Keyword + "%"}
+	if query.Category != "" {
+		q += " AND categories.name = ?"
+		args = append(args, query.Category)
+	}
+	if query.MinPrice > 0 {
+		q += " AND items.price >= ?"
+		args = append(args, query.MinPrice)
+	}
+	if query.MaxPrice > 0 {
+		q += " AND items.price <= ?"
+		args = append(args, query.MaxPrice)
+	}
+	// sorted so that the same filters always build the same query
+	for _, name := range slices.Sorted(maps.Keys(query.Attributes)) {
+		q += " AND EXISTS (SELECT 1 FROM item_attributes WHERE item_attributes.item_id = items.id AND item_attributes.name = ? AND item_attributes.value = ?)"
+		args = append(args, name, query.Attributes[name])
+	}
+
+	rows, err := i.db.QueryContext(ctx, q, args...)
 
 	return rows, err
 }
@@ -158,33 +675,319 @@ func StoreImage(fileName string, image []byte) error {
 }
 
 func InitDB(dbPath string) (*sql.DB, error) {
-	database, err := sql.Open("sqlite3", dbPath)
+	database, err := sql.Open(sqliteDriverName, dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	createCategoriesTableQuery := `
-	CREATE TABLE IF NOT EXISTS categories(
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT UNIQUE NOT NULL
-	);`
-	_, err = database.Exec(createCategoriesTableQuery)
+	for _, t := range schemaTables {
+		if _, err := database.Exec(t.query); err != nil {
+			return nil, fmt.Errorf("failed to create %s table: %w", t.name, err)
+		}
+	}
+
+	// databases created before these columns existed do not get them from CREATE TABLE IF NOT EXISTS
+	for _, c := range itemsAddedColumns {
+		if err := ensureColumn(database, "items", c.name, c.definition); err != nil {
+			return nil, err
+		}
+	}
+	if err := ensureColumn(database, "saved_searches", "attributes", "TEXT NOT NULL DEFAULT '{}'"); err != nil {
+		return nil, err
+	}
+	if err := normalizeItemsCreatedAt(database); err != nil {
+		return nil, err
+	}
+
+	_, err = database.Exec("CREATE INDEX IF NOT EXISTS idx_items_seller_id ON items(seller_id, created_at)")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create categories table: %w", err)
+		return nil, fmt.Errorf("failed to create items index: %w", err)
 	}
+	_, err = database.Exec("CREATE INDEX IF NOT EXISTS idx_items_publish_at ON items(status, publish_at)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create items index: %w", err)
+	}
+
+	if err := seedAttributeSchemas(database); err != nil {
+		return nil, err
+	}
+
+	return database, nil
+}
 
-	createItemsTableQuery := `
+// schemaTables are the tables created by InitDB with their indexes, in the order they are created.
+// The indexes of the items table are created after its added columns.
+var schemaTables = []struct{ name, query string }{
+	{"categories", `
+	CREATE TABLE IF NOT EXISTS categories(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT UNIQUE NOT NULL
+	);`},
+	{"items", `
 	CREATE TABLE IF NOT EXISTS items(
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		name TEXT NOT NULL,
 		category_id INTEGER NOT NULL,
 		image_name TEXT NOT NULL,
+		seller_id INTEGER NOT NULL DEFAULT 0,
+		price INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL DEFAULT 'on_sale',
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		publish_at TIMESTAMP,
+		FOREIGN KEY (category_id) REFERENCES categories(id)
+	);`},
+	{"comments", `
+	CREATE TABLE IF NOT EXISTS comments(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_comments_item_id ON comments(item_id, id);`},
+	{"offers", `
+	CREATE TABLE IF NOT EXISTS offers(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		buyer_id INTEGER NOT NULL,
+		price INTEGER NOT NULL,
+		counter_price INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_offers_item_id ON offers(item_id, status);`},
+	{"orders", `
+	CREATE TABLE IF NOT EXISTS orders(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL UNIQUE,
+		buyer_id INTEGER NOT NULL,
+		seller_id INTEGER NOT NULL,
+		price INTEGER NOT NULL,
+		offer_id INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);`},
+	{"conversations", `
+	CREATE TABLE IF NOT EXISTS conversations(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		order_id INTEGER NOT NULL UNIQUE,
+		buyer_id INTEGER NOT NULL,
+		seller_id INTEGER NOT NULL,
+		buyer_last_read_id INTEGER NOT NULL DEFAULT 0,
+		seller_last_read_id INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (order_id) REFERENCES orders(id)
+	);`},
+	{"messages", `
+	CREATE TABLE IF NOT EXISTS messages(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		conversation_id INTEGER NOT NULL,
+		sender_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (conversation_id) REFERENCES conversations(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_messages_conversation_id ON messages(conversation_id, id);`},
+	{"ratings", `
+	CREATE TABLE IF NOT EXISTS ratings(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		order_id INTEGER NOT NULL,
+		rater_id INTEGER NOT NULL,
+		ratee_id INTEGER NOT NULL,
+		rating TEXT NOT NULL CHECK (rating IN ('good', 'normal', 'bad')),
+		comment TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE (order_id, rater_id),
+		FOREIGN KEY (order_id) REFERENCES orders(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_ratings_ratee_id ON ratings(ratee_id);`},
+	{"follows", `
+	CREATE TABLE IF NOT EXISTS follows(
+		follower_id INTEGER NOT NULL,
+		seller_id INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (follower_id, seller_id)
+	);`},
+	{"saved_searches", `
+	CREATE TABLE IF NOT EXISTS saved_searches(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		keyword TEXT NOT NULL,
+		category TEXT NOT NULL DEFAULT '',
+		min_price INTEGER NOT NULL DEFAULT 0,
+		max_price INTEGER NOT NULL DEFAULT 0,
+		attributes TEXT NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches(user_id);`},
+	{"notifications", `
+	CREATE TABLE IF NOT EXISTS notifications(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		item_id INTEGER NOT NULL DEFAULT 0,
+		message TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, id);`},
+	{"price_history", `
+	CREATE TABLE IF NOT EXISTS price_history(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		old_price INTEGER NOT NULL,
+		new_price INTEGER NOT NULL,
+		changed_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_price_history_item_id ON price_history(item_id, id);`},
+	{"watches", `
+	CREATE TABLE IF NOT EXISTS watches(
+		user_id INTEGER NOT NULL,
+		item_id INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (user_id, item_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_watches_item_id ON watches(item_id, user_id);`},
+	{"category_attributes", `
+	CREATE TABLE IF NOT EXISTS category_attributes(
+		category_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		required BOOLEAN NOT NULL DEFAULT FALSE,
+		options TEXT NOT NULL DEFAULT 'null',
+		position INTEGER NOT NULL,
+		PRIMARY KEY (category_id, name),
 		FOREIGN KEY (category_id) REFERENCES categories(id)
-	);`
-	_, err = database.Exec(createItemsTableQuery)
+	);`},
+	{"item_attributes", `
+	CREATE TABLE IF NOT EXISTS item_attributes(
+		item_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (item_id, name),
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_item_attributes_name_value ON item_attributes(name, value);`},
+	{"moderation_rules", `
+	CREATE TABLE IF NOT EXISTS moderation_rules(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		category_id INTEGER NOT NULL DEFAULT 0,
+		word TEXT NOT NULL DEFAULT '',
+		reason TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);`},
+	{"moderation_reviews", `
+	CREATE TABLE IF NOT EXISTS moderation_reviews(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		reasons TEXT NOT NULL,
+		status TEXT NOT NULL,
+		reviewer_id INTEGER NOT NULL DEFAULT 0,
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		reviewed_at TIMESTAMP,
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_moderation_reviews_status ON moderation_reviews(status, id);`},
+	{"moderation_decisions", `
+	CREATE TABLE IF NOT EXISTS moderation_decisions(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		moderator_id INTEGER NOT NULL DEFAULT 0,
+		action TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_moderation_decisions_item_id ON moderation_decisions(item_id, id);`},
+	{"reports", `
+	CREATE TABLE IF NOT EXISTS reports(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		reporter_id INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		comment TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE (item_id, reporter_id),
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_reports_status_item_id ON reports(status, item_id);`},
+	{"item_events", `
+	CREATE TABLE IF NOT EXISTS item_events(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		user_id INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_item_events_created_at ON item_events(created_at);`},
+	{"item_daily_views", `
+	CREATE TABLE IF NOT EXISTS item_daily_views(
+		item_id INTEGER NOT NULL,
+		day TEXT NOT NULL,
+		views INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (item_id, day),
+		FOREIGN KEY (item_id) REFERENCES items(id)
+	);`},
+}
+
+// itemsAddedColumns are the columns added to the items table after it was first released.
+var itemsAddedColumns = []struct{ name, definition string }{
+	{"seller_id", "INTEGER NOT NULL DEFAULT 0"},
+	{"price", "INTEGER NOT NULL DEFAULT 0"},
+	{"status", "TEXT NOT NULL DEFAULT 'on_sale'"},
+	// ALTER TABLE does not accept CURRENT_TIMESTAMP, so existing items are dated to the epoch
+	{"created_at", "TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00'"},
+	{"publish_at", "TIMESTAMP"},
+}
+
+// normalizeItemsCreatedAt stores the creation times written by SQLite, such as the epoch of the items listed
+// before created_at was added, in the format of the times bound by the driver, with their UTC offset,
+// so that created_at compares and orders as text with the bound times and its index can be used.
+func normalizeItemsCreatedAt(database *sql.DB) error {
+	_, err := database.Exec("UPDATE items SET created_at = created_at || '+00:00' WHERE length(created_at) = 19")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create item table: %w", err)
+		return fmt.Errorf("failed to normalize creation times of items: %w", err)
 	}
+	return nil
+}
 
-	return database, nil
+// ensureColumn adds a column to an existing table if it is missing.
+func ensureColumn(database *sql.DB, table, column, definition string) error {
+	rows, err := database.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to get columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return fmt.Errorf("failed to scan columns of %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get columns of %s: %w", table, err)
+	}
+	rows.Close()
+
+	_, err = database.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("failed to add %s to %s: %w", column, table, err)
+	}
+	return nil
 }
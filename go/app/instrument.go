@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedItemRepository records a span and the latency of every operation of an ItemRepository.
+type instrumentedItemRepository struct {
+	ItemRepository
+	metrics *Metrics
+}
+
+// instrumentItemRepository wraps the repository to trace and time its operations.
+func instrumentItemRepository(repo ItemRepository, m *Metrics) ItemRepository {
+	return &instrumentedItemRepository{ItemRepository: repo, metrics: m}
+}
+
+// start starts the span of an operation. The returned function ends it with the error of the operation.
+func (r *instrumentedItemRepository) start(ctx context.Context, operation string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := startSpan(ctx, "ItemRepository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNameSQLite, semconv.DBOperationName(operation)),
+	)
+	return ctx, func(err error) {
+		endSpan(span, err)
+		r.metrics.observeItemQuery(operation, start)
+	}
+}
+
+func (r *instrumentedItemRepository) Insert(ctx context.Context, item *Item) (err error) {
+	ctx, end := r.start(ctx, "Insert")
+	defer func() { end(err) }()
+	return r.ItemRepository.Insert(ctx, item)
+}
+
+func (r *instrumentedItemRepository) InsertQuarantined(ctx context.Context, item *Item, reasons []string) (_ *ModerationReview, err error) {
+	ctx, end := r.start(ctx, "InsertQuarantined")
+	defer func() { end(err) }()
+	return r.ItemRepository.InsertQuarantined(ctx, item, reasons)
+}
+
+func (r *instrumentedItemRepository) GetAll(ctx context.Context) (_ []Item, err error) {
+	ctx, end := r.start(ctx, "GetAll")
+	defer func() { end(err) }()
+	return r.ItemRepository.GetAll(ctx)
+}
+
+func (r *instrumentedItemRepository) GetByID(ctx context.Context, itemID int) (_ *Item, err error) {
+	ctx, end := r.start(ctx, "GetByID")
+	defer func() { end(err) }()
+	return r.ItemRepository.GetByID(ctx, itemID)
+}
+
+func (r *instrumentedItemRepository) GetCategoryID(ctx context.Context, categoryName string) (_ int, err error) {
+	ctx, end := r.start(ctx, "GetCategoryID")
+	defer func() { end(err) }()
+	return r.ItemRepository.GetCategoryID(ctx, categoryName)
+}
+
+// Search only traces the query, as the rows are read by the caller.
+func (r *instrumentedItemRepository) Search(ctx context.Context, query *SearchQuery) (_ *sql.Rows, err error) {
+	ctx, end := r.start(ctx, "Search")
+	defer func() { end(err) }()
+	return r.ItemRepository.Search(ctx, query)
+}
+
+func (r *instrumentedItemRepository) Update(ctx context.Context, item *Item) (err error) {
+	ctx, end := r.start(ctx, "Update")
+	defer func() { end(err) }()
+	return r.ItemRepository.Update(ctx, item)
+}
+
+func (r *instrumentedItemRepository) UpdateQuarantined(ctx context.Context, item *Item, reasons []string) (_ *ModerationReview, err error) {
+	ctx, end := r.start(ctx, "UpdateQuarantined")
+	defer func() { end(err) }()
+	return r.ItemRepository.UpdateQuarantined(ctx, item, reasons)
+}
+
+func (r *instrumentedItemRepository) GetPriceHistory(ctx context.Context, itemID int) (_ []PriceChange, err error) {
+	ctx, end := r.start(ctx, "GetPriceHistory")
+	defer func() { end(err) }()
+	return r.ItemRepository.GetPriceHistory(ctx, itemID)
+}
+
+func (r *instrumentedItemRepository) GetCategoryName(ctx context.Context, categoryID int) (_ string, err error) {
+	ctx, end := r.start(ctx, "GetCategoryName")
+	defer func() { end(err) }()
+	return r.ItemRepository.GetCategoryName(ctx, categoryID)
+}
+
+func (r *instrumentedItemRepository) Publish(ctx context.Context, itemID int) (err error) {
+	ctx, end := r.start(ctx, "Publish")
+	defer func() { end(err) }()
+	return r.ItemRepository.Publish(ctx, itemID)
+}
+
+func (r *instrumentedItemRepository) ListDue(ctx context.Context, now time.Time) (_ []Item, err error) {
+	ctx, end := r.start(ctx, "ListDue")
+	defer func() { end(err) }()
+	return r.ItemRepository.ListDue(ctx, now)
+}
+
+func (r *instrumentedItemRepository) ListDrafts(ctx context.Context, sellerID int) (_ []Item, err error) {
+	ctx, end := r.start(ctx, "ListDrafts")
+	defer func() { end(err) }()
+	return r.ItemRepository.ListDrafts(ctx, sellerID)
+}
+
+func (r *instrumentedItemRepository) HasListedItems(ctx context.Context, sellerID int) (_ bool, err error) {
+	ctx, end := r.start(ctx, "HasListedItems")
+	defer func() { end(err) }()
+	return r.ItemRepository.HasListedItems(ctx, sellerID)
+}
+
+func (r *instrumentedItemRepository) ListComparable(ctx context.Context, category string, keywords []string, limit int) (_ []Item, err error) {
+	ctx, end := r.start(ctx, "ListComparable")
+	defer func() { end(err) }()
+	return r.ItemRepository.ListComparable(ctx, category, keywords, limit)
+}
+
+func (r *instrumentedItemRepository) ListByIDs(ctx context.Context, itemIDs []int) (_ []Item, err error) {
+	ctx, end := r.start(ctx, "ListByIDs")
+	defer func() { end(err) }()
+	return r.ItemRepository.ListByIDs(ctx, itemIDs)
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Job is a unit of work run in the background.
+type Job func(ctx context.Context)
+
+// JobQueue runs jobs in background goroutines so that handlers can respond without waiting for them.
+type JobQueue struct {
+	jobs    chan Job
+	workers int
+	wg      sync.WaitGroup
+
+	// mu guards closed, so that the jobs enqueued while stopping are dropped instead of panicking.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewJobQueue creates a JobQueue buffering up to size jobs and running them on the given number of workers.
+func NewJobQueue(size, workers int) *JobQueue {
+	return &JobQueue{jobs: make(chan Job, size), workers: workers}
+}
+
+// Start starts the workers. The context is passed to every job.
+func (q *JobQueue) Start(ctx context.Context) {
+	for range q.workers {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for job := range q.jobs {
+				q.run(ctx, job)
+			}
+		}()
+	}
+}
+
+// run runs a job, keeping a panicking job from taking the worker down.
+func (q *JobQueue) run(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "background job panicked", "panic", r)
+		}
+	}()
+	job(ctx)
+}
+
+// Enqueue adds a job without blocking. It returns false if the queue is full or stopped and the job was dropped.
+func (q *JobQueue) Enqueue(job Job) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop stops accepting jobs and waits for the queued ones to finish.
+func (q *JobQueue) Stop() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		header string
+		// keep is whether the ID of the header is used
+		keep bool
+	}{
+		"ok: client ID":   {header: "req-1234.abcd:5", keep: true},
+		"ok: generated":   {},
+		"ng: invalid ID":  {header: "id with\nnewline"},
+		"ng: too long ID": {header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got string
+			handler := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = requestIDFromContext(r.Context())
+			}))
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(requestIDHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tt.keep && got != tt.header {
+				t.Errorf("expected the ID of the client %q, got %q", tt.header, got)
+			}
+			if !tt.keep && (got == tt.header || !validRequestID(got)) {
+				t.Errorf("expected a generated ID, got %q", got)
+			}
+			if header := rr.Header().Get(requestIDHeader); header != got {
+				t.Errorf("expected the response header %q, got %q", got, header)
+			}
+		})
+	}
+}
+
+func TestRequestLoggerMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, slog.LevelInfo)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{item_id}", func(w http.ResponseWriter, r *http.Request) {
+		// the logs of the handlers carry the request ID too
+		logger.WarnContext(r.Context(), "item not found")
+		http.Error(w, "item not found", http.StatusNotFound)
+	})
+	handler := requestIDMiddleware(requestLoggerMiddleware(mux, logger))
+
+	req := httptest.NewRequest("GET", "/items/1", nil)
+	req.Header.Set(requestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d: %s", len(lines), buf.String())
+	}
+	var handlerLog, requestLog map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &handlerLog); err != nil {
+		t.Fatalf("failed to decode record: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &requestLog); err != nil {
+		t.Fatalf("failed to decode record: %v", err)
+	}
+
+	if handlerLog["request_id"] != "req-1" {
+		t.Errorf("expected the request ID in the handler log, got %v", handlerLog)
+	}
+	for key, want := range map[string]any{
+		"msg":        "request completed",
+		"request_id": "req-1",
+		"method":     "GET",
+		"route":      "GET /items/{item_id}",
+		"status":     float64(http.StatusNotFound),
+		"bytes":      float64(len("item not found\n")),
+	} {
+		if requestLog[key] != want {
+			t.Errorf("expected %s %v, got %v", key, want, requestLog[key])
+		}
+	}
+	if _, ok := requestLog["duration_ms"]; !ok {
+		t.Errorf("expected the duration, got %v", requestLog)
+	}
+}
+
+func TestContextHandler(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, slog.LevelInfo).With("component", "test")
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(withRequestID(context.Background(), "req-2"),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	logger.InfoContext(ctx, "with context")
+	logger.Info("without context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var withCtx, withoutCtx map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &withCtx); err != nil {
+		t.Fatalf("failed to decode record: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &withoutCtx); err != nil {
+		t.Fatalf("failed to decode record: %v", err)
+	}
+
+	for key, want := range map[string]any{
+		"request_id": "req-2",
+		"trace_id":   traceID.String(),
+		"span_id":    spanID.String(),
+		"component":  "test",
+	} {
+		if withCtx[key] != want {
+			t.Errorf("expected %s %v, got %v", key, want, withCtx[key])
+		}
+	}
+	if _, ok := withoutCtx["request_id"]; ok {
+		t.Errorf("expected no request ID without a context, got %v", withoutCtx)
+	}
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const maxMessageLength = 1000
+
+// parseConversationID parses the conversation ID from the path.
+func parseConversationID(r *http.Request) (int, error) {
+	conversationID, err := strconv.Atoi(r.PathValue("conversation_id"))
+	if err != nil || conversationID < 1 {
+		return 0, errors.New("invalid conversation ID")
+	}
+	return conversationID, nil
+}
+
+// participantConversation loads the conversation in the path and checks that the user takes part in it.
+// It writes the error response and returns nil otherwise.
+func (s *Handlers) participantConversation(w http.ResponseWriter, r *http.Request, userID int) *Conversation {
+	conversationID, err := parseConversationID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return nil
+	}
+
+	conv, err := s.conversationRepo.GetByID(r.Context(), conversationID)
+	if err != nil && !errors.Is(err, errConversationNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get conversation: %w", err))
+		return nil
+	}
+	// outsiders cannot tell whether the conversation exists
+	if err != nil || !conv.IsParticipant(userID) {
+		writeError(w, r, errConversationNotFound)
+		return nil
+	}
+	return conv
+}
+
+// GetConversations is a handler to return the conversations of the user with unread counts for GET /conversations .
+func (s *Handlers) GetConversations(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	convs, err := s.conversationRepo.ListByUserID(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get conversations: %w", err))
+		return
+	}
+
+	unread := 0
+	for _, c := range convs {
+		unread += c.UnreadCount
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Conversations []Conversation `json:"conversations"`
+		UnreadCount   int            `json:"unread_count"`
+	}{Conversations: convs, UnreadCount: unread})
+}
+
+// GetOrderConversation is a handler to return the conversation of an order for GET /orders/{order_id}/conversation .
+func (s *Handlers) GetOrderConversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	orderID, err := parseOrderID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil && !errors.Is(err, errOrderNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get order: %w", err))
+		return
+	}
+	if err != nil || order.BuyerID != userID && order.SellerID != userID {
+		writeError(w, r, errOrderNotFound)
+		return
+	}
+
+	// the conversation is normally created on purchase, but orders made before messaging existed have none
+	conv, err := s.conversationRepo.CreateForOrder(ctx, order)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get conversation: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, conv)
+}
+
+// GetMessages is a handler to return the messages of a conversation for GET /conversations/{conversation_id}/messages .
+// The returned messages are marked as read by the user.
+func (s *Handlers) GetMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	conv := s.participantConversation(w, r, userID)
+	if conv == nil {
+		return
+	}
+
+	messages, err := s.conversationRepo.ListMessages(ctx, conv.ID, limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get messages: %w", err))
+		return
+	}
+
+	if len(messages) > 0 {
+		last := messages[len(messages)-1].ID
+		if err := s.conversationRepo.MarkRead(ctx, conv.ID, userID, last); err != nil {
+			// the messages can still be returned even if the unread counter is stale
+			slog.ErrorContext(ctx, "failed to mark messages as read: ", "error", err)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Messages []Message `json:"messages"`
+		Limit    int       `json:"limit"`
+		Offset   int       `json:"offset"`
+	}{Messages: messages, Limit: limit, Offset: offset})
+}
+
+// AddMessage is a handler to send a message for POST /conversations/{conversation_id}/messages .
+func (s *Handlers) AddMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	body := strings.TrimSpace(r.FormValue("body"))
+	var v validator
+	if v.required("body", body) {
+		v.maxLength("body", body, maxMessageLength)
+	}
+	if err := v.err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	conv := s.participantConversation(w, r, userID)
+	if conv == nil {
+		return
+	}
+
+	message := &Message{ConversationID: conv.ID, SenderID: userID, Body: body}
+	if err := s.conversationRepo.InsertMessage(ctx, message); err != nil {
+		writeError(w, r, fmt.Errorf("failed to store message: %w", err))
+		return
+	}
+	// the sender has obviously read everything up to their own message
+	if err := s.conversationRepo.MarkRead(ctx, conv.ID, userID, message.ID); err != nil {
+		slog.ErrorContext(ctx, "failed to mark messages as read: ", "error", err)
+	}
+
+	writeJSON(w, http.StatusCreated, message)
+}
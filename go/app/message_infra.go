@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var errConversationNotFound = errors.New("conversation not found")
+
+// Conversation is the private channel between the buyer and the seller of an order.
+type Conversation struct {
+	ID        int       `db:"id" json:"id"`
+	OrderID   int       `db:"order_id" json:"order_id"`
+	BuyerID   int       `db:"buyer_id" json:"buyer_id"`
+	SellerID  int       `db:"seller_id" json:"seller_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// UnreadCount is the number of messages the requesting user has not read yet.
+	UnreadCount int `db:"unread_count" json:"unread_count"`
+}
+
+// IsParticipant reports whether the user is the buyer or the seller of the conversation.
+func (c *Conversation) IsParticipant(userID int) bool {
+	return userID == c.BuyerID || userID == c.SellerID
+}
+
+type Message struct {
+	ID             int       `db:"id" json:"id"`
+	ConversationID int       `db:"conversation_id" json:"conversation_id"`
+	SenderID       int       `db:"sender_id" json:"sender_id"`
+	Body           string    `db:"body" json:"body"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// ConversationRepository is an interface to manage conversations and their messages.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type ConversationRepository interface {
+	// CreateForOrder returns the conversation of the order, creating it if it does not exist yet.
+	CreateForOrder(ctx context.Context, order *Order) (*Conversation, error)
+	GetByID(ctx context.Context, conversationID int) (*Conversation, error)
+	// ListByUserID returns the conversations of the user with their unread counts, most recently active first.
+	ListByUserID(ctx context.Context, userID int) ([]Conversation, error)
+	InsertMessage(ctx context.Context, message *Message) error
+	ListMessages(ctx context.Context, conversationID, limit, offset int) ([]Message, error)
+	// MarkRead marks the messages up to messageID as read by the user.
+	MarkRead(ctx context.Context, conversationID, userID, messageID int) error
+}
+
+// conversationRepository is an implementation of ConversationRepository
+type conversationRepository struct {
+	db *sql.DB
+}
+
+// NewConversationRepository creates a new conversationRepository.
+func NewConversationRepository(database *sql.DB) ConversationRepository {
+	return &conversationRepository{db: database}
+}
+
+func (c *conversationRepository) CreateForOrder(ctx context.Context, order *Order) (*Conversation, error) {
+	_, err := c.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO conversations (order_id, buyer_id, seller_id, created_at)
+		VALUES (?, ?, ?, ?)`, order.ID, order.BuyerID, order.SellerID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert conversation: %w", err)
+	}
+
+	var conv Conversation
+	err = c.db.QueryRowContext(ctx, "SELECT id, order_id, buyer_id, seller_id, created_at FROM conversations WHERE order_id = ?", order.ID).
+		Scan(&conv.ID, &conv.OrderID, &conv.BuyerID, &conv.SellerID, &conv.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation: %w", err)
+	}
+	return &conv, nil
+}
+
+func (c *conversationRepository) GetByID(ctx context.Context, conversationID int) (*Conversation, error) {
+	var conv Conversation
+	err := c.db.QueryRowContext(ctx, "SELECT id, order_id, buyer_id, seller_id, created_at FROM conversations WHERE id = ?", conversationID).
+		Scan(&conv.ID, &conv.OrderID, &conv.BuyerID, &conv.SellerID, &conv.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errConversationNotFound
+		}
+		return nil, fmt.Errorf("failed to query conversation: %w", err)
+	}
+	return &conv, nil
+}
+
+func (c *conversationRepository) ListByUserID(ctx context.Context, userID int) ([]Conversation, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT c.id, c.order_id, c.buyer_id, c.seller_id, c.created_at,
+			(SELECT COUNT(*) FROM messages m
+			 WHERE m.conversation_id = c.id AND m.sender_id <> ?
+			   AND m.id > CASE WHEN c.buyer_id = ? THEN c.buyer_last_read_id ELSE c.seller_last_read_id END) AS unread_count
+		FROM conversations c
+		WHERE c.buyer_id = ? OR c.seller_id = ?
+		ORDER BY COALESCE((SELECT MAX(id) FROM messages WHERE conversation_id = c.id), 0) DESC, c.id DESC`,
+		userID, userID, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversations: %w", err)
+	}
+	defer rows.Close()
+
+	convs := []Conversation{}
+	for rows.Next() {
+		var conv Conversation
+		if err := rows.Scan(&conv.ID, &conv.OrderID, &conv.BuyerID, &conv.SellerID, &conv.CreatedAt, &conv.UnreadCount); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		convs = append(convs, conv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate conversations: %w", err)
+	}
+
+	return convs, nil
+}
+
+func (c *conversationRepository) InsertMessage(ctx context.Context, message *Message) error {
+	message.CreatedAt = time.Now().UTC()
+	res, err := c.db.ExecContext(ctx, "INSERT INTO messages (conversation_id, sender_id, body, created_at) VALUES (?, ?, ?, ?)",
+		message.ConversationID, message.SenderID, message.Body, message.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert message: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get message id: %w", err)
+	}
+	message.ID = int(id)
+	return nil
+}
+
+// ListMessages returns the messages of a conversation, oldest first.
+func (c *conversationRepository) ListMessages(ctx context.Context, conversationID, limit, offset int) ([]Message, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT id, conversation_id, sender_id, body, created_at FROM messages
+		WHERE conversation_id = ?
+		ORDER BY id
+		LIMIT ? OFFSET ?`, conversationID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []Message{}
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (c *conversationRepository) MarkRead(ctx context.Context, conversationID, userID, messageID int) error {
+	_, err := c.db.ExecContext(ctx, `
+		UPDATE conversations SET
+			buyer_last_read_id = CASE WHEN buyer_id = ? THEN MAX(buyer_last_read_id, ?) ELSE buyer_last_read_id END,
+			seller_last_read_id = CASE WHEN seller_id = ? THEN MAX(seller_last_read_id, ?) ELSE seller_last_read_id END
+		WHERE id = ?`, userID, messageID, userID, messageID, conversationID)
+	if err != nil {
+		return fmt.Errorf("failed to mark messages as read: %w", err)
+	}
+	return nil
+}
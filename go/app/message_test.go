@@ -0,0 +1,131 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetMessages(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID   string
+		injector func(m *MockConversationRepository)
+		wants
+	}{
+		"ok: buyer reads and marks as read": {
+			userID: "2",
+			injector: func(m *MockConversationRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 7).Return(&Conversation{ID: 7, BuyerID: 2, SellerID: 1}, nil).Times(1)
+				m.EXPECT().ListMessages(gomock.Any(), 7, defaultPageLimit, 0).Return([]Message{{ID: 3}, {ID: 4}}, nil).Times(1)
+				m.EXPECT().MarkRead(gomock.Any(), 7, 2, 4).Return(nil).Times(1)
+			},
+			wants: wants{code: http.StatusOK},
+		},
+		"ng: outsider cannot read": {
+			userID: "3",
+			injector: func(m *MockConversationRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 7).Return(&Conversation{ID: 7, BuyerID: 2, SellerID: 1}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+		"ng: user ID is missing": {
+			injector: func(m *MockConversationRepository) {},
+			wants:    wants{code: http.StatusUnauthorized},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockCR := NewMockConversationRepository(ctrl)
+			tt.injector(mockCR)
+			h := &Handlers{conversationRepo: mockCR}
+
+			req := httptest.NewRequest("GET", "/conversations/7/messages", nil)
+			req.SetPathValue("conversation_id", "7")
+			if tt.userID != "" {
+				req.Header.Set(userIDHeader, tt.userID)
+			}
+
+			rr := httptest.NewRecorder()
+			h.GetMessages(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+		})
+	}
+}
+
+func TestAddMessage(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID   string
+		body     string
+		injector func(m *MockConversationRepository)
+		wants
+	}{
+		"ok: seller sends a message": {
+			userID: "1",
+			body:   "Shipped today.",
+			injector: func(m *MockConversationRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 7).Return(&Conversation{ID: 7, BuyerID: 2, SellerID: 1}, nil).Times(1)
+				m.EXPECT().InsertMessage(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().MarkRead(gomock.Any(), 7, 1, gomock.Any()).Return(nil).Times(1)
+			},
+			wants: wants{code: http.StatusCreated},
+		},
+		"ng: outsider cannot send": {
+			userID: "3",
+			body:   "Hello",
+			injector: func(m *MockConversationRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 7).Return(&Conversation{ID: 7, BuyerID: 2, SellerID: 1}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+		"ng: body is empty": {
+			userID:   "1",
+			injector: func(m *MockConversationRepository) {},
+			wants:    wants{code: http.StatusUnprocessableEntity},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockCR := NewMockConversationRepository(ctrl)
+			tt.injector(mockCR)
+			h := &Handlers{conversationRepo: mockCR}
+
+			form := url.Values{"body": {tt.body}}
+			req := httptest.NewRequest("POST", "/conversations/7/messages", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set(userIDHeader, tt.userID)
+			req.SetPathValue("conversation_id", "7")
+
+			rr := httptest.NewRecorder()
+			h.AddMessage(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+		})
+	}
+}
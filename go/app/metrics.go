@@ -0,0 +1,126 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute is the route label of the requests matching no pattern, so that
+// arbitrary paths don't create new series.
+const unmatchedRoute = "unmatched"
+
+// The directions of the image bytes.
+const (
+	imageBytesStored = "stored"
+	imageBytesServed = "served"
+)
+
+// Metrics holds the Prometheus metrics of the server, exposed on GET /metrics .
+// A nil *Metrics records nothing, so that handlers can be tested without it.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests          *prometheus.CounterVec
+	requestDuration   *prometheus.HistogramVec
+	requestsInFlight  prometheus.Gauge
+	itemQueryDuration *prometheus.HistogramVec
+	imageBytes        *prometheus.CounterVec
+}
+
+// NewMetrics creates the metrics on a registry of their own, along with the Go runtime
+// and process metrics.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "mercari",
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route pattern and status code.",
+		}, []string{"route", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "mercari",
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route"}),
+		requestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "mercari",
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served.",
+		}),
+		itemQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "mercari",
+			Name:      "item_query_duration_seconds",
+			Help:      "Latency of the operations of the item repository by method.",
+			// from 0.5ms to about 1s
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 12),
+		}, []string{"operation"}),
+		imageBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "mercari",
+			Name:      "image_bytes_total",
+			Help:      "Bytes of images stored by uploads and served to clients.",
+		}, []string{"direction"}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.requestsInFlight,
+		m.itemQueryDuration,
+		m.imageBytes,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler returns the handler exposing the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// observeRequest records a served request.
+func (m *Metrics) observeRequest(route string, code int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(route, strconv.Itoa(code)).Inc()
+	m.requestDuration.WithLabelValues(route).Observe(d.Seconds())
+}
+
+// observeItemQuery records the latency of an operation of the item repository.
+func (m *Metrics) observeItemQuery(operation string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.itemQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// addImageBytes counts the bytes of images stored or served.
+func (m *Metrics) addImageBytes(direction string, n int64) {
+	if m == nil || n == 0 {
+		return
+	}
+	m.imageBytes.WithLabelValues(direction).Add(float64(n))
+}
+
+// byteCountingResponseWriter counts the bytes of the response body.
+type byteCountingResponseWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *byteCountingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *byteCountingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
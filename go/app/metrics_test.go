@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	m := NewMetrics()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{item_id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("item_id") == "0" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	handler := metricsMiddleware(mux, m)
+
+	for _, path := range []string{"/items/1", "/items/2", "/items/0", "/unknown/3", "/unknown/4"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	cases := []struct {
+		route, code string
+		want        float64
+	}{
+		{route: "GET /items/{item_id}", code: "200", want: 2},
+		{route: "GET /items/{item_id}", code: "404", want: 1},
+		{route: unmatchedRoute, code: "404", want: 2},
+	}
+	for _, c := range cases {
+		if got := testutil.ToFloat64(m.requests.WithLabelValues(c.route, c.code)); got != c.want {
+			t.Errorf("requests of %q with %s: expected %v, got %v", c.route, c.code, c.want, got)
+		}
+	}
+	if got := testutil.CollectAndCount(m.requestDuration); got != 2 {
+		t.Errorf("expected latencies of 2 routes, got %d", got)
+	}
+	if got := testutil.ToFloat64(m.requestsInFlight); got != 0 {
+		t.Errorf("expected no request in flight, got %v", got)
+	}
+
+	// the runtime metrics are exposed along with the ones above
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	for _, name := range []string{"mercari_http_requests_total", "go_goroutines"} {
+		if !strings.Contains(rr.Body.String(), name) {
+			t.Errorf("expected %s in the exposition", name)
+		}
+	}
+}
+
+func TestInstrumentItemRepository(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockIR := NewMockItemRepository(ctrl)
+	mockIR.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1}, nil).Times(2)
+
+	m := NewMetrics()
+	repo := instrumentItemRepository(mockIR, m)
+	for range 2 {
+		if _, err := repo.GetByID(context.Background(), 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	var series []string
+	for _, f := range families {
+		if f.GetName() != "mercari_item_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range f.GetMetric() {
+			var labels []string
+			for _, l := range metric.GetLabel() {
+				labels = append(labels, l.GetName()+"="+l.GetValue())
+			}
+			series = append(series, fmt.Sprintf("%s %d", strings.Join(labels, ","), metric.GetHistogram().GetSampleCount()))
+		}
+	}
+	if diff := cmp.Diff([]string{"operation=GetByID 2"}, series); diff != "" {
+		t.Errorf("unexpected query durations (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreImage(t *testing.T) {
+	t.Parallel()
+
+	h := &Handlers{imgDirPath: t.TempDir(), metrics: NewMetrics()}
+	cases := map[string]struct {
+		image   []byte
+		wantExt string
+		wantErr bool
+	}{
+		"ok: jpeg":         {image: testJPEG, wantExt: ".jpg"},
+		"ok: png":          {image: testPNG, wantExt: ".png"},
+		"ng: not an image": {image: []byte("dummy image data"), wantErr: true},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			fileName, err := h.storeImage(context.Background(), tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got := filepath.Ext(fileName); got != tt.wantExt {
+				t.Errorf("expected extension %q, got %q", tt.wantExt, got)
+			}
+		})
+	}
+}
+
+func TestImageBytesMetrics(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := NewMetrics()
+	h := &Handlers{imgDirPath: dir, metrics: m}
+
+	image := testJPEG
+	fileName, err := h.storeImage(context.Background(), image)
+	if err != nil {
+		t.Fatalf("failed to store image: %v", err)
+	}
+	// storing the same image again writes nothing
+	if _, err := h.storeImage(context.Background(), image); err != nil {
+		t.Fatalf("failed to store image: %v", err)
+	}
+	if got := testutil.ToFloat64(m.imageBytes.WithLabelValues(imageBytesStored)); got != float64(len(image)) {
+		t.Errorf("expected %d stored bytes, got %v", len(image), got)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, fileName)); err != nil {
+		t.Fatalf("stored image not found: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/images/"+fileName, nil)
+	req.SetPathValue("filename", fileName)
+	h.GetImage(httptest.NewRecorder(), req)
+	if got := testutil.ToFloat64(m.imageBytes.WithLabelValues(imageBytesServed)); got != float64(len(image)) {
+		t.Errorf("expected %d served bytes, got %v", len(image), got)
+	}
+}
@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: analytics_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=analytics_infra.go -package=app -destination=./mock_analytics_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAnalyticsRepository is a mock of AnalyticsRepository interface.
+type MockAnalyticsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAnalyticsRepositoryMockRecorder is the mock recorder for MockAnalyticsRepository.
+type MockAnalyticsRepositoryMockRecorder struct {
+	mock *MockAnalyticsRepository
+}
+
+// NewMockAnalyticsRepository creates a new mock instance.
+func NewMockAnalyticsRepository(ctrl *gomock.Controller) *MockAnalyticsRepository {
+	mock := &MockAnalyticsRepository{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyticsRepository) EXPECT() *MockAnalyticsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetItemDailyViews mocks base method.
+func (m *MockAnalyticsRepository) GetItemDailyViews(ctx context.Context, itemID int, from, to time.Time) ([]DailyViews, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemDailyViews", ctx, itemID, from, to)
+	ret0, _ := ret[0].([]DailyViews)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemDailyViews indicates an expected call of GetItemDailyViews.
+func (mr *MockAnalyticsRepositoryMockRecorder) GetItemDailyViews(ctx, itemID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemDailyViews", reflect.TypeOf((*MockAnalyticsRepository)(nil).GetItemDailyViews), ctx, itemID, from, to)
+}
+
+// GetSellerDailyViews mocks base method.
+func (m *MockAnalyticsRepository) GetSellerDailyViews(ctx context.Context, sellerID int, from, to time.Time) ([]DailyViews, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSellerDailyViews", ctx, sellerID, from, to)
+	ret0, _ := ret[0].([]DailyViews)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSellerDailyViews indicates an expected call of GetSellerDailyViews.
+func (mr *MockAnalyticsRepositoryMockRecorder) GetSellerDailyViews(ctx, sellerID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSellerDailyViews", reflect.TypeOf((*MockAnalyticsRepository)(nil).GetSellerDailyViews), ctx, sellerID, from, to)
+}
+
+// ListSellerItemViews mocks base method.
+func (m *MockAnalyticsRepository) ListSellerItemViews(ctx context.Context, sellerID int, from, to time.Time) ([]ItemViews, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSellerItemViews", ctx, sellerID, from, to)
+	ret0, _ := ret[0].([]ItemViews)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSellerItemViews indicates an expected call of ListSellerItemViews.
+func (mr *MockAnalyticsRepositoryMockRecorder) ListSellerItemViews(ctx, sellerID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSellerItemViews", reflect.TypeOf((*MockAnalyticsRepository)(nil).ListSellerItemViews), ctx, sellerID, from, to)
+}
+
+// RecordViews mocks base method.
+func (m *MockAnalyticsRepository) RecordViews(ctx context.Context, views []ItemEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordViews", ctx, views)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordViews indicates an expected call of RecordViews.
+func (mr *MockAnalyticsRepositoryMockRecorder) RecordViews(ctx, views any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordViews", reflect.TypeOf((*MockAnalyticsRepository)(nil).RecordViews), ctx, views)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: attribute_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=attribute_infra.go -package=app -destination=./mock_attribute_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAttributeSchemaRepository is a mock of AttributeSchemaRepository interface.
+type MockAttributeSchemaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttributeSchemaRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAttributeSchemaRepositoryMockRecorder is the mock recorder for MockAttributeSchemaRepository.
+type MockAttributeSchemaRepositoryMockRecorder struct {
+	mock *MockAttributeSchemaRepository
+}
+
+// NewMockAttributeSchemaRepository creates a new mock instance.
+func NewMockAttributeSchemaRepository(ctrl *gomock.Controller) *MockAttributeSchemaRepository {
+	mock := &MockAttributeSchemaRepository{ctrl: ctrl}
+	mock.recorder = &MockAttributeSchemaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttributeSchemaRepository) EXPECT() *MockAttributeSchemaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetSchema mocks base method.
+func (m *MockAttributeSchemaRepository) GetSchema(ctx context.Context, categoryID int) ([]AttributeDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchema", ctx, categoryID)
+	ret0, _ := ret[0].([]AttributeDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchema indicates an expected call of GetSchema.
+func (mr *MockAttributeSchemaRepositoryMockRecorder) GetSchema(ctx, categoryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchema", reflect.TypeOf((*MockAttributeSchemaRepository)(nil).GetSchema), ctx, categoryID)
+}
+
+// SetSchema mocks base method.
+func (m *MockAttributeSchemaRepository) SetSchema(ctx context.Context, categoryID int, schema []AttributeDefinition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSchema", ctx, categoryID, schema)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSchema indicates an expected call of SetSchema.
+func (mr *MockAttributeSchemaRepositoryMockRecorder) SetSchema(ctx, categoryID, schema any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSchema", reflect.TypeOf((*MockAttributeSchemaRepository)(nil).SetSchema), ctx, categoryID, schema)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: follow_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=follow_infra.go -package=app -destination=./mock_follow_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFollowRepository is a mock of FollowRepository interface.
+type MockFollowRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockFollowRepositoryMockRecorder is the mock recorder for MockFollowRepository.
+type MockFollowRepositoryMockRecorder struct {
+	mock *MockFollowRepository
+}
+
+// NewMockFollowRepository creates a new mock instance.
+func NewMockFollowRepository(ctrl *gomock.Controller) *MockFollowRepository {
+	mock := &MockFollowRepository{ctrl: ctrl}
+	mock.recorder = &MockFollowRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowRepository) EXPECT() *MockFollowRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Follow mocks base method.
+func (m *MockFollowRepository) Follow(ctx context.Context, followerID, sellerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Follow", ctx, followerID, sellerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockFollowRepositoryMockRecorder) Follow(ctx, followerID, sellerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockFollowRepository)(nil).Follow), ctx, followerID, sellerID)
+}
+
+// ListFeedItems mocks base method.
+func (m *MockFollowRepository) ListFeedItems(ctx context.Context, followerID int, after *FeedCursor, limit int) ([]Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeedItems", ctx, followerID, after, limit)
+	ret0, _ := ret[0].([]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeedItems indicates an expected call of ListFeedItems.
+func (mr *MockFollowRepositoryMockRecorder) ListFeedItems(ctx, followerID, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeedItems", reflect.TypeOf((*MockFollowRepository)(nil).ListFeedItems), ctx, followerID, after, limit)
+}
+
+// Unfollow mocks base method.
+func (m *MockFollowRepository) Unfollow(ctx context.Context, followerID, sellerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfollow", ctx, followerID, sellerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unfollow indicates an expected call of Unfollow.
+func (mr *MockFollowRepositoryMockRecorder) Unfollow(ctx, followerID, sellerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfollow", reflect.TypeOf((*MockFollowRepository)(nil).Unfollow), ctx, followerID, sellerID)
+}
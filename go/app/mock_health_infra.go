@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: health_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=health_infra.go -package=app -destination=./mock_health_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHealthRepository is a mock of HealthRepository interface.
+type MockHealthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockHealthRepositoryMockRecorder is the mock recorder for MockHealthRepository.
+type MockHealthRepositoryMockRecorder struct {
+	mock *MockHealthRepository
+}
+
+// NewMockHealthRepository creates a new mock instance.
+func NewMockHealthRepository(ctrl *gomock.Controller) *MockHealthRepository {
+	mock := &MockHealthRepository{ctrl: ctrl}
+	mock.recorder = &MockHealthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthRepository) EXPECT() *MockHealthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// MissingSchema mocks base method.
+func (m *MockHealthRepository) MissingSchema(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MissingSchema", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MissingSchema indicates an expected call of MissingSchema.
+func (mr *MockHealthRepositoryMockRecorder) MissingSchema(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MissingSchema", reflect.TypeOf((*MockHealthRepository)(nil).MissingSchema), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockHealthRepositoryMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockHealthRepository)(nil).Ping), ctx)
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -86,6 +87,51 @@ func (mr *MockItemRepositoryMockRecorder) GetCategoryID(ctx, categoryName any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryID", reflect.TypeOf((*MockItemRepository)(nil).GetCategoryID), ctx, categoryName)
 }
 
+// GetCategoryName mocks base method.
+func (m *MockItemRepository) GetCategoryName(ctx context.Context, categoryID int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryName", ctx, categoryID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryName indicates an expected call of GetCategoryName.
+func (mr *MockItemRepositoryMockRecorder) GetCategoryName(ctx, categoryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryName", reflect.TypeOf((*MockItemRepository)(nil).GetCategoryName), ctx, categoryID)
+}
+
+// GetPriceHistory mocks base method.
+func (m *MockItemRepository) GetPriceHistory(ctx context.Context, itemID int) ([]PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceHistory", ctx, itemID)
+	ret0, _ := ret[0].([]PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceHistory indicates an expected call of GetPriceHistory.
+func (mr *MockItemRepositoryMockRecorder) GetPriceHistory(ctx, itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceHistory", reflect.TypeOf((*MockItemRepository)(nil).GetPriceHistory), ctx, itemID)
+}
+
+// HasListedItems mocks base method.
+func (m *MockItemRepository) HasListedItems(ctx context.Context, sellerID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasListedItems", ctx, sellerID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasListedItems indicates an expected call of HasListedItems.
+func (mr *MockItemRepositoryMockRecorder) HasListedItems(ctx, sellerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasListedItems", reflect.TypeOf((*MockItemRepository)(nil).HasListedItems), ctx, sellerID)
+}
+
 // Insert mocks base method.
 func (m *MockItemRepository) Insert(ctx context.Context, item *Item) error {
 	m.ctrl.T.Helper()
@@ -100,17 +146,135 @@ func (mr *MockItemRepositoryMockRecorder) Insert(ctx, item any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockItemRepository)(nil).Insert), ctx, item)
 }
 
-// SearchByKeyword mocks base method.
-func (m *MockItemRepository) SearchByKeyword(ctx context.Context, keyword string) (*sql.Rows, error) {
+// InsertQuarantined mocks base method.
+func (m *MockItemRepository) InsertQuarantined(ctx context.Context, item *Item, reasons []string) (*ModerationReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertQuarantined", ctx, item, reasons)
+	ret0, _ := ret[0].(*ModerationReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertQuarantined indicates an expected call of InsertQuarantined.
+func (mr *MockItemRepositoryMockRecorder) InsertQuarantined(ctx, item, reasons any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertQuarantined", reflect.TypeOf((*MockItemRepository)(nil).InsertQuarantined), ctx, item, reasons)
+}
+
+// ListByIDs mocks base method.
+func (m *MockItemRepository) ListByIDs(ctx context.Context, itemIDs []int) ([]Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByIDs", ctx, itemIDs)
+	ret0, _ := ret[0].([]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByIDs indicates an expected call of ListByIDs.
+func (mr *MockItemRepositoryMockRecorder) ListByIDs(ctx, itemIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByIDs", reflect.TypeOf((*MockItemRepository)(nil).ListByIDs), ctx, itemIDs)
+}
+
+// ListComparable mocks base method.
+func (m *MockItemRepository) ListComparable(ctx context.Context, category string, keywords []string, limit int) ([]Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListComparable", ctx, category, keywords, limit)
+	ret0, _ := ret[0].([]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListComparable indicates an expected call of ListComparable.
+func (mr *MockItemRepositoryMockRecorder) ListComparable(ctx, category, keywords, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComparable", reflect.TypeOf((*MockItemRepository)(nil).ListComparable), ctx, category, keywords, limit)
+}
+
+// ListDrafts mocks base method.
+func (m *MockItemRepository) ListDrafts(ctx context.Context, sellerID int) ([]Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDrafts", ctx, sellerID)
+	ret0, _ := ret[0].([]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDrafts indicates an expected call of ListDrafts.
+func (mr *MockItemRepositoryMockRecorder) ListDrafts(ctx, sellerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDrafts", reflect.TypeOf((*MockItemRepository)(nil).ListDrafts), ctx, sellerID)
+}
+
+// ListDue mocks base method.
+func (m *MockItemRepository) ListDue(ctx context.Context, now time.Time) ([]Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDue", ctx, now)
+	ret0, _ := ret[0].([]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDue indicates an expected call of ListDue.
+func (mr *MockItemRepositoryMockRecorder) ListDue(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDue", reflect.TypeOf((*MockItemRepository)(nil).ListDue), ctx, now)
+}
+
+// Publish mocks base method.
+func (m *MockItemRepository) Publish(ctx context.Context, itemID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, itemID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockItemRepositoryMockRecorder) Publish(ctx, itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockItemRepository)(nil).Publish), ctx, itemID)
+}
+
+// Search mocks base method.
+func (m *MockItemRepository) Search(ctx context.Context, query *SearchQuery) (*sql.Rows, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchByKeyword", ctx, keyword)
+	ret := m.ctrl.Call(m, "Search", ctx, query)
 	ret0, _ := ret[0].(*sql.Rows)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchByKeyword indicates an expected call of SearchByKeyword.
-func (mr *MockItemRepositoryMockRecorder) SearchByKeyword(ctx, keyword any) *gomock.Call {
+// Search indicates an expected call of Search.
+func (mr *MockItemRepositoryMockRecorder) Search(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockItemRepository)(nil).Search), ctx, query)
+}
+
+// Update mocks base method.
+func (m *MockItemRepository) Update(ctx context.Context, item *Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockItemRepositoryMockRecorder) Update(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockItemRepository)(nil).Update), ctx, item)
+}
+
+// UpdateQuarantined mocks base method.
+func (m *MockItemRepository) UpdateQuarantined(ctx context.Context, item *Item, reasons []string) (*ModerationReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQuarantined", ctx, item, reasons)
+	ret0, _ := ret[0].(*ModerationReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateQuarantined indicates an expected call of UpdateQuarantined.
+func (mr *MockItemRepositoryMockRecorder) UpdateQuarantined(ctx, item, reasons any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByKeyword", reflect.TypeOf((*MockItemRepository)(nil).SearchByKeyword), ctx, keyword)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQuarantined", reflect.TypeOf((*MockItemRepository)(nil).UpdateQuarantined), ctx, item, reasons)
 }
@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: message_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=message_infra.go -package=app -destination=./mock_message_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockConversationRepository is a mock of ConversationRepository interface.
+type MockConversationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockConversationRepositoryMockRecorder is the mock recorder for MockConversationRepository.
+type MockConversationRepositoryMockRecorder struct {
+	mock *MockConversationRepository
+}
+
+// NewMockConversationRepository creates a new mock instance.
+func NewMockConversationRepository(ctrl *gomock.Controller) *MockConversationRepository {
+	mock := &MockConversationRepository{ctrl: ctrl}
+	mock.recorder = &MockConversationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversationRepository) EXPECT() *MockConversationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateForOrder mocks base method.
+func (m *MockConversationRepository) CreateForOrder(ctx context.Context, order *Order) (*Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateForOrder", ctx, order)
+	ret0, _ := ret[0].(*Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateForOrder indicates an expected call of CreateForOrder.
+func (mr *MockConversationRepositoryMockRecorder) CreateForOrder(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateForOrder", reflect.TypeOf((*MockConversationRepository)(nil).CreateForOrder), ctx, order)
+}
+
+// GetByID mocks base method.
+func (m *MockConversationRepository) GetByID(ctx context.Context, conversationID int) (*Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, conversationID)
+	ret0, _ := ret[0].(*Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockConversationRepositoryMockRecorder) GetByID(ctx, conversationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockConversationRepository)(nil).GetByID), ctx, conversationID)
+}
+
+// InsertMessage mocks base method.
+func (m *MockConversationRepository) InsertMessage(ctx context.Context, message *Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertMessage", ctx, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertMessage indicates an expected call of InsertMessage.
+func (mr *MockConversationRepositoryMockRecorder) InsertMessage(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertMessage", reflect.TypeOf((*MockConversationRepository)(nil).InsertMessage), ctx, message)
+}
+
+// ListByUserID mocks base method.
+func (m *MockConversationRepository) ListByUserID(ctx context.Context, userID int) ([]Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", ctx, userID)
+	ret0, _ := ret[0].([]Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockConversationRepositoryMockRecorder) ListByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockConversationRepository)(nil).ListByUserID), ctx, userID)
+}
+
+// ListMessages mocks base method.
+func (m *MockConversationRepository) ListMessages(ctx context.Context, conversationID, limit, offset int) ([]Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessages", ctx, conversationID, limit, offset)
+	ret0, _ := ret[0].([]Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMessages indicates an expected call of ListMessages.
+func (mr *MockConversationRepositoryMockRecorder) ListMessages(ctx, conversationID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessages", reflect.TypeOf((*MockConversationRepository)(nil).ListMessages), ctx, conversationID, limit, offset)
+}
+
+// MarkRead mocks base method.
+func (m *MockConversationRepository) MarkRead(ctx context.Context, conversationID, userID, messageID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, conversationID, userID, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockConversationRepositoryMockRecorder) MarkRead(ctx, conversationID, userID, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockConversationRepository)(nil).MarkRead), ctx, conversationID, userID, messageID)
+}
@@ -0,0 +1,158 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: moderation_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=moderation_infra.go -package=app -destination=./mock_moderation_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockModerationRepository is a mock of ModerationRepository interface.
+type MockModerationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockModerationRepositoryMockRecorder is the mock recorder for MockModerationRepository.
+type MockModerationRepositoryMockRecorder struct {
+	mock *MockModerationRepository
+}
+
+// NewMockModerationRepository creates a new mock instance.
+func NewMockModerationRepository(ctrl *gomock.Controller) *MockModerationRepository {
+	mock := &MockModerationRepository{ctrl: ctrl}
+	mock.recorder = &MockModerationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationRepository) EXPECT() *MockModerationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRule mocks base method.
+func (m *MockModerationRepository) DeleteRule(ctx context.Context, ruleID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRule", ctx, ruleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRule indicates an expected call of DeleteRule.
+func (mr *MockModerationRepositoryMockRecorder) DeleteRule(ctx, ruleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRule", reflect.TypeOf((*MockModerationRepository)(nil).DeleteRule), ctx, ruleID)
+}
+
+// GetReview mocks base method.
+func (m *MockModerationRepository) GetReview(ctx context.Context, reviewID int) (*ModerationReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReview", ctx, reviewID)
+	ret0, _ := ret[0].(*ModerationReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReview indicates an expected call of GetReview.
+func (mr *MockModerationRepositoryMockRecorder) GetReview(ctx, reviewID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReview", reflect.TypeOf((*MockModerationRepository)(nil).GetReview), ctx, reviewID)
+}
+
+// InsertRule mocks base method.
+func (m *MockModerationRepository) InsertRule(ctx context.Context, rule *ModerationRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertRule indicates an expected call of InsertRule.
+func (mr *MockModerationRepositoryMockRecorder) InsertRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRule", reflect.TypeOf((*MockModerationRepository)(nil).InsertRule), ctx, rule)
+}
+
+// ListDecisions mocks base method.
+func (m *MockModerationRepository) ListDecisions(ctx context.Context, itemID, limit, offset int) ([]ModerationDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDecisions", ctx, itemID, limit, offset)
+	ret0, _ := ret[0].([]ModerationDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDecisions indicates an expected call of ListDecisions.
+func (mr *MockModerationRepositoryMockRecorder) ListDecisions(ctx, itemID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDecisions", reflect.TypeOf((*MockModerationRepository)(nil).ListDecisions), ctx, itemID, limit, offset)
+}
+
+// ListReviews mocks base method.
+func (m *MockModerationRepository) ListReviews(ctx context.Context, status ReviewStatus, limit, offset int) ([]ModerationReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReviews", ctx, status, limit, offset)
+	ret0, _ := ret[0].([]ModerationReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReviews indicates an expected call of ListReviews.
+func (mr *MockModerationRepositoryMockRecorder) ListReviews(ctx, status, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReviews", reflect.TypeOf((*MockModerationRepository)(nil).ListReviews), ctx, status, limit, offset)
+}
+
+// ListRules mocks base method.
+func (m *MockModerationRepository) ListRules(ctx context.Context, categoryID int) ([]ModerationRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRules", ctx, categoryID)
+	ret0, _ := ret[0].([]ModerationRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRules indicates an expected call of ListRules.
+func (mr *MockModerationRepositoryMockRecorder) ListRules(ctx, categoryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRules", reflect.TypeOf((*MockModerationRepository)(nil).ListRules), ctx, categoryID)
+}
+
+// Quarantine mocks base method.
+func (m *MockModerationRepository) Quarantine(ctx context.Context, itemID int, reasons []string) (*ModerationReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quarantine", ctx, itemID, reasons)
+	ret0, _ := ret[0].(*ModerationReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quarantine indicates an expected call of Quarantine.
+func (mr *MockModerationRepositoryMockRecorder) Quarantine(ctx, itemID, reasons any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quarantine", reflect.TypeOf((*MockModerationRepository)(nil).Quarantine), ctx, itemID, reasons)
+}
+
+// Resolve mocks base method.
+func (m *MockModerationRepository) Resolve(ctx context.Context, review *ModerationReview, itemStatus ItemStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, review, itemStatus)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockModerationRepositoryMockRecorder) Resolve(ctx, review, itemStatus any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockModerationRepository)(nil).Resolve), ctx, review, itemStatus)
+}
@@ -0,0 +1,128 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: offer_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=offer_infra.go -package=app -destination=./mock_offer_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOfferRepository is a mock of OfferRepository interface.
+type MockOfferRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOfferRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOfferRepositoryMockRecorder is the mock recorder for MockOfferRepository.
+type MockOfferRepositoryMockRecorder struct {
+	mock *MockOfferRepository
+}
+
+// NewMockOfferRepository creates a new mock instance.
+func NewMockOfferRepository(ctrl *gomock.Controller) *MockOfferRepository {
+	mock := &MockOfferRepository{ctrl: ctrl}
+	mock.recorder = &MockOfferRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOfferRepository) EXPECT() *MockOfferRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Accept mocks base method.
+func (m *MockOfferRepository) Accept(ctx context.Context, offer *Offer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", ctx, offer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Accept indicates an expected call of Accept.
+func (mr *MockOfferRepositoryMockRecorder) Accept(ctx, offer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockOfferRepository)(nil).Accept), ctx, offer)
+}
+
+// ExpireStale mocks base method.
+func (m *MockOfferRepository) ExpireStale(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireStale", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireStale indicates an expected call of ExpireStale.
+func (mr *MockOfferRepositoryMockRecorder) ExpireStale(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireStale", reflect.TypeOf((*MockOfferRepository)(nil).ExpireStale), ctx, now)
+}
+
+// GetByID mocks base method.
+func (m *MockOfferRepository) GetByID(ctx context.Context, offerID int) (*Offer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, offerID)
+	ret0, _ := ret[0].(*Offer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockOfferRepositoryMockRecorder) GetByID(ctx, offerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockOfferRepository)(nil).GetByID), ctx, offerID)
+}
+
+// Insert mocks base method.
+func (m *MockOfferRepository) Insert(ctx context.Context, offer *Offer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, offer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockOfferRepositoryMockRecorder) Insert(ctx, offer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockOfferRepository)(nil).Insert), ctx, offer)
+}
+
+// ListByItemID mocks base method.
+func (m *MockOfferRepository) ListByItemID(ctx context.Context, itemID int) ([]Offer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByItemID", ctx, itemID)
+	ret0, _ := ret[0].([]Offer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByItemID indicates an expected call of ListByItemID.
+func (mr *MockOfferRepositoryMockRecorder) ListByItemID(ctx, itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByItemID", reflect.TypeOf((*MockOfferRepository)(nil).ListByItemID), ctx, itemID)
+}
+
+// Update mocks base method.
+func (m *MockOfferRepository) Update(ctx context.Context, offer *Offer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, offer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOfferRepositoryMockRecorder) Update(ctx, offer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOfferRepository)(nil).Update), ctx, offer)
+}
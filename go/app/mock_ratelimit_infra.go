@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ratelimit_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=ratelimit_infra.go -package=app -destination=./mock_ratelimit_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRateLimitStore is a mock of RateLimitStore interface.
+type MockRateLimitStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockRateLimitStoreMockRecorder is the mock recorder for MockRateLimitStore.
+type MockRateLimitStoreMockRecorder struct {
+	mock *MockRateLimitStore
+}
+
+// NewMockRateLimitStore creates a new mock instance.
+func NewMockRateLimitStore(ctrl *gomock.Controller) *MockRateLimitStore {
+	mock := &MockRateLimitStore{ctrl: ctrl}
+	mock.recorder = &MockRateLimitStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitStore) EXPECT() *MockRateLimitStoreMockRecorder {
+	return m.recorder
+}
+
+// Take mocks base method.
+func (m *MockRateLimitStore) Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Take", ctx, key, limit, now)
+	ret0, _ := ret[0].(RateLimitResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Take indicates an expected call of Take.
+func (mr *MockRateLimitStoreMockRecorder) Take(ctx, key, limit, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Take", reflect.TypeOf((*MockRateLimitStore)(nil).Take), ctx, key, limit, now)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rating_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=rating_infra.go -package=app -destination=./mock_rating_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRatingRepository is a mock of RatingRepository interface.
+type MockRatingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRatingRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRatingRepositoryMockRecorder is the mock recorder for MockRatingRepository.
+type MockRatingRepositoryMockRecorder struct {
+	mock *MockRatingRepository
+}
+
+// NewMockRatingRepository creates a new mock instance.
+func NewMockRatingRepository(ctrl *gomock.Controller) *MockRatingRepository {
+	mock := &MockRatingRepository{ctrl: ctrl}
+	mock.recorder = &MockRatingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRatingRepository) EXPECT() *MockRatingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetSellerSummary mocks base method.
+func (m *MockRatingRepository) GetSellerSummary(ctx context.Context, userID int) (*RatingSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSellerSummary", ctx, userID)
+	ret0, _ := ret[0].(*RatingSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSellerSummary indicates an expected call of GetSellerSummary.
+func (mr *MockRatingRepositoryMockRecorder) GetSellerSummary(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSellerSummary", reflect.TypeOf((*MockRatingRepository)(nil).GetSellerSummary), ctx, userID)
+}
+
+// Insert mocks base method.
+func (m *MockRatingRepository) Insert(ctx context.Context, rating *Rating) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, rating)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockRatingRepositoryMockRecorder) Insert(ctx, rating any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockRatingRepository)(nil).Insert), ctx, rating)
+}
@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: report_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=report_infra.go -package=app -destination=./mock_report_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReportRepository is a mock of ReportRepository interface.
+type MockReportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockReportRepositoryMockRecorder is the mock recorder for MockReportRepository.
+type MockReportRepositoryMockRecorder struct {
+	mock *MockReportRepository
+}
+
+// NewMockReportRepository creates a new mock instance.
+func NewMockReportRepository(ctrl *gomock.Controller) *MockReportRepository {
+	mock := &MockReportRepository{ctrl: ctrl}
+	mock.recorder = &MockReportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportRepository) EXPECT() *MockReportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Hide mocks base method.
+func (m *MockReportRepository) Hide(ctx context.Context, itemID int, decision *ModerationDecision) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hide", ctx, itemID, decision)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hide indicates an expected call of Hide.
+func (mr *MockReportRepositoryMockRecorder) Hide(ctx, itemID, decision any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hide", reflect.TypeOf((*MockReportRepository)(nil).Hide), ctx, itemID, decision)
+}
+
+// Insert mocks base method.
+func (m *MockReportRepository) Insert(ctx context.Context, report *Report) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, report)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockReportRepositoryMockRecorder) Insert(ctx, report any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockReportRepository)(nil).Insert), ctx, report)
+}
+
+// ListOpenByItemID mocks base method.
+func (m *MockReportRepository) ListOpenByItemID(ctx context.Context, itemID int) ([]Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenByItemID", ctx, itemID)
+	ret0, _ := ret[0].([]Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenByItemID indicates an expected call of ListOpenByItemID.
+func (mr *MockReportRepositoryMockRecorder) ListOpenByItemID(ctx, itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenByItemID", reflect.TypeOf((*MockReportRepository)(nil).ListOpenByItemID), ctx, itemID)
+}
+
+// ListOpenSummaries mocks base method.
+func (m *MockReportRepository) ListOpenSummaries(ctx context.Context, limit, offset int) ([]ItemReportSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenSummaries", ctx, limit, offset)
+	ret0, _ := ret[0].([]ItemReportSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenSummaries indicates an expected call of ListOpenSummaries.
+func (mr *MockReportRepositoryMockRecorder) ListOpenSummaries(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenSummaries", reflect.TypeOf((*MockReportRepository)(nil).ListOpenSummaries), ctx, limit, offset)
+}
+
+// Resolve mocks base method.
+func (m *MockReportRepository) Resolve(ctx context.Context, itemID int, status ReportStatus, itemStatus ItemStatus, decision *ModerationDecision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, itemID, status, itemStatus, decision)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockReportRepositoryMockRecorder) Resolve(ctx, itemID, status, itemStatus, decision any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockReportRepository)(nil).Resolve), ctx, itemID, status, itemStatus, decision)
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: savedsearch_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=savedsearch_infra.go -package=app -destination=./mock_savedsearch_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSavedSearchRepository is a mock of SavedSearchRepository interface.
+type MockSavedSearchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSavedSearchRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSavedSearchRepositoryMockRecorder is the mock recorder for MockSavedSearchRepository.
+type MockSavedSearchRepositoryMockRecorder struct {
+	mock *MockSavedSearchRepository
+}
+
+// NewMockSavedSearchRepository creates a new mock instance.
+func NewMockSavedSearchRepository(ctrl *gomock.Controller) *MockSavedSearchRepository {
+	mock := &MockSavedSearchRepository{ctrl: ctrl}
+	mock.recorder = &MockSavedSearchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSavedSearchRepository) EXPECT() *MockSavedSearchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockSavedSearchRepository) Delete(ctx context.Context, searchID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, searchID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSavedSearchRepositoryMockRecorder) Delete(ctx, searchID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSavedSearchRepository)(nil).Delete), ctx, searchID, userID)
+}
+
+// Insert mocks base method.
+func (m *MockSavedSearchRepository) Insert(ctx context.Context, search *SavedSearch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, search)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockSavedSearchRepositoryMockRecorder) Insert(ctx, search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockSavedSearchRepository)(nil).Insert), ctx, search)
+}
+
+// ListByUserID mocks base method.
+func (m *MockSavedSearchRepository) ListByUserID(ctx context.Context, userID int) ([]SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", ctx, userID)
+	ret0, _ := ret[0].([]SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockSavedSearchRepositoryMockRecorder) ListByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockSavedSearchRepository)(nil).ListByUserID), ctx, userID)
+}
+
+// ListMatching mocks base method.
+func (m *MockSavedSearchRepository) ListMatching(ctx context.Context, item *Item, category string) ([]SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMatching", ctx, item, category)
+	ret0, _ := ret[0].([]SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMatching indicates an expected call of ListMatching.
+func (mr *MockSavedSearchRepositoryMockRecorder) ListMatching(ctx, item, category any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMatching", reflect.TypeOf((*MockSavedSearchRepository)(nil).ListMatching), ctx, item, category)
+}
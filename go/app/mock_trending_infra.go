@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: trending_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=trending_infra.go -package=app -destination=./mock_trending_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEventRepository is a mock of EventRepository interface.
+type MockEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEventRepositoryMockRecorder is the mock recorder for MockEventRepository.
+type MockEventRepositoryMockRecorder struct {
+	mock *MockEventRepository
+}
+
+// NewMockEventRepository creates a new mock instance.
+func NewMockEventRepository(ctrl *gomock.Controller) *MockEventRepository {
+	mock := &MockEventRepository{ctrl: ctrl}
+	mock.recorder = &MockEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteBefore mocks base method.
+func (m *MockEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockEventRepositoryMockRecorder) DeleteBefore(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockEventRepository)(nil).DeleteBefore), ctx, before)
+}
+
+// Record mocks base method.
+func (m *MockEventRepository) Record(ctx context.Context, event *ItemEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockEventRepositoryMockRecorder) Record(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockEventRepository)(nil).Record), ctx, event)
+}
+
+// SumDecayed mocks base method.
+func (m *MockEventRepository) SumDecayed(ctx context.Context, since, now time.Time, halfLife time.Duration) ([]ItemEventTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumDecayed", ctx, since, now, halfLife)
+	ret0, _ := ret[0].([]ItemEventTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumDecayed indicates an expected call of SumDecayed.
+func (mr *MockEventRepositoryMockRecorder) SumDecayed(ctx, since, now, halfLife any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumDecayed", reflect.TypeOf((*MockEventRepository)(nil).SumDecayed), ctx, since, now, halfLife)
+}
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: watch_infra.go
+//
+// Generated by this command:
+//
+//	mockgen -source=watch_infra.go -package=app -destination=./mock_watch_infra.go
+//
+
+// Package app is a generated GoMock package.
+package app
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWatchRepository is a mock of WatchRepository interface.
+type MockWatchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatchRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockWatchRepositoryMockRecorder is the mock recorder for MockWatchRepository.
+type MockWatchRepositoryMockRecorder struct {
+	mock *MockWatchRepository
+}
+
+// NewMockWatchRepository creates a new mock instance.
+func NewMockWatchRepository(ctrl *gomock.Controller) *MockWatchRepository {
+	mock := &MockWatchRepository{ctrl: ctrl}
+	mock.recorder = &MockWatchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatchRepository) EXPECT() *MockWatchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListItems mocks base method.
+func (m *MockWatchRepository) ListItems(ctx context.Context, userID int) ([]Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItems", ctx, userID)
+	ret0, _ := ret[0].([]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItems indicates an expected call of ListItems.
+func (mr *MockWatchRepositoryMockRecorder) ListItems(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItems", reflect.TypeOf((*MockWatchRepository)(nil).ListItems), ctx, userID)
+}
+
+// ListWatcherIDs mocks base method.
+func (m *MockWatchRepository) ListWatcherIDs(ctx context.Context, itemID, afterUserID, limit int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWatcherIDs", ctx, itemID, afterUserID, limit)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWatcherIDs indicates an expected call of ListWatcherIDs.
+func (mr *MockWatchRepositoryMockRecorder) ListWatcherIDs(ctx, itemID, afterUserID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWatcherIDs", reflect.TypeOf((*MockWatchRepository)(nil).ListWatcherIDs), ctx, itemID, afterUserID, limit)
+}
+
+// Unwatch mocks base method.
+func (m *MockWatchRepository) Unwatch(ctx context.Context, userID, itemID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unwatch", ctx, userID, itemID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unwatch indicates an expected call of Unwatch.
+func (mr *MockWatchRepositoryMockRecorder) Unwatch(ctx, userID, itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unwatch", reflect.TypeOf((*MockWatchRepository)(nil).Unwatch), ctx, userID, itemID)
+}
+
+// Watch mocks base method.
+func (m *MockWatchRepository) Watch(ctx context.Context, userID, itemID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, userID, itemID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockWatchRepositoryMockRecorder) Watch(ctx, userID, itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockWatchRepository)(nil).Watch), ctx, userID, itemID)
+}
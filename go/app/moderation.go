@@ -0,0 +1,334 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var errNotAdmin = errors.New("admin privileges are required")
+
+// parseAdminUserIDs parses the comma-separated IDs of the admin users.
+func parseAdminUserIDs(v string) ([]int, error) {
+	var ids []int
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		id, err := strconv.Atoi(s)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("invalid admin user ID: %q", s)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseAdminID returns the ID of the user sending the request, who must be an admin.
+func (s *Handlers) parseAdminID(r *http.Request) (int, error) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		return 0, err
+	}
+	if !s.adminIDs[userID] {
+		return 0, errNotAdmin
+	}
+	return userID, nil
+}
+
+// normalizeForModeration folds the variations of Japanese and Latin text so that banned words
+// match however they are written: full-width and half-width forms are unified by NFKC,
+// katakana is turned into hiragana, letters are lowercased, and spaces and symbols are removed.
+func normalizeForModeration(s string) string {
+	s = norm.NFKC.String(s)
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'ァ' && r <= 'ヶ':
+			// katakana and hiragana are 0x60 apart
+			b.WriteRune(r - 0x60)
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// checkModeration returns the reasons the item should be reviewed before being listed, or nil if it can be listed.
+func (s *Handlers) checkModeration(ctx context.Context, item *Item) ([]string, error) {
+	rules, err := s.moderationRepo.ListRules(ctx, item.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := []string{normalizeForModeration(item.Name)}
+	for _, value := range item.Attributes {
+		texts = append(texts, normalizeForModeration(value))
+	}
+
+	var reasons []string
+	for _, rule := range rules {
+		if rule.CategoryID != 0 && rule.CategoryID != item.CategoryID {
+			continue
+		}
+		if rule.Word != "" && !slices.ContainsFunc(texts, func(t string) bool { return strings.Contains(t, rule.Word) }) {
+			continue
+		}
+		if !slices.Contains(reasons, rule.Reason) {
+			reasons = append(reasons, rule.Reason)
+		}
+	}
+	return reasons, nil
+}
+
+// quarantineIfFlagged runs the moderation rules on an item already stored and quarantines it if it is flagged.
+// It returns whether the item has been quarantined.
+func (s *Handlers) quarantineIfFlagged(ctx context.Context, item *Item) (bool, error) {
+	reasons, err := s.checkModeration(ctx, item)
+	if err != nil || len(reasons) == 0 {
+		return false, err
+	}
+	if _, err := s.moderationRepo.Quarantine(ctx, item.ID, reasons); err != nil {
+		return false, err
+	}
+	item.Status = ItemStatusQuarantined
+	slog.InfoContext(ctx, "item quarantined", "item_id", item.ID, "reasons", reasons)
+	return true, nil
+}
+
+// GetModerationRules is a handler to return the moderation rules for GET /admin/moderation/rules .
+func (s *Handlers) GetModerationRules(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.parseAdminID(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	rules, err := s.moderationRepo.ListRules(r.Context(), 0)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get moderation rules: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Rules []ModerationRule `json:"rules"`
+	}{Rules: rules})
+}
+
+// AddModerationRule is a handler to add a moderation rule for POST /admin/moderation/rules .
+// A rule has a banned word, a category or both. A category without a word prohibits the whole category.
+func (s *Handlers) AddModerationRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := s.parseAdminID(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	word := normalizeForModeration(r.FormValue("word"))
+	category := strings.TrimSpace(r.FormValue("category"))
+	if word == "" && category == "" {
+		writeError(w, r, newValidationError("word", "word or category is required"))
+		return
+	}
+
+	rule := &ModerationRule{Word: word, Reason: strings.TrimSpace(r.FormValue("reason"))}
+	if rule.Reason == "" {
+		rule.Reason = "prohibited item"
+		if word != "" {
+			rule.Reason = fmt.Sprintf("contains banned word %q", word)
+		}
+	}
+	if category != "" {
+		var err error
+		rule.CategoryID, err = s.itemRepo.GetCategoryID(ctx, category)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("failed to get category ID: %w", err))
+			return
+		}
+	}
+
+	if err := s.moderationRepo.InsertRule(ctx, rule); err != nil {
+		writeError(w, r, fmt.Errorf("failed to store moderation rule: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// DeleteModerationRule is a handler to delete a moderation rule for DELETE /admin/moderation/rules/{rule_id} .
+func (s *Handlers) DeleteModerationRule(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.parseAdminID(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	ruleID, err := strconv.Atoi(r.PathValue("rule_id"))
+	if err != nil || ruleID < 1 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, "invalid rule ID"))
+		return
+	}
+
+	if err := s.moderationRepo.DeleteRule(r.Context(), ruleID); err != nil {
+		writeError(w, r, fmt.Errorf("failed to delete moderation rule: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetModerationReviews is a handler to return the review queue for GET /admin/moderation/reviews .
+// It returns the pending reviews unless another status is given.
+func (s *Handlers) GetModerationReviews(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.parseAdminID(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	status := ReviewStatusPending
+	if s := r.URL.Query().Get("status"); s != "" {
+		var v validator
+		v.oneOf("status", s, string(ReviewStatusPending), string(ReviewStatusApproved), string(ReviewStatusRejected))
+		if err := v.err(); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		status = ReviewStatus(s)
+	}
+
+	reviews, err := s.moderationRepo.ListReviews(r.Context(), status, limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get moderation reviews: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Reviews []ModerationReview `json:"reviews"`
+		Limit   int                `json:"limit"`
+		Offset  int                `json:"offset"`
+	}{Reviews: reviews, Limit: limit, Offset: offset})
+}
+
+// GetModerationDecisions is a handler to return the audit trail of the moderation decisions for GET /admin/moderation/decisions .
+// It returns the decisions about a single item if item_id is given.
+func (s *Handlers) GetModerationDecisions(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.parseAdminID(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	var itemID int
+	if v := r.URL.Query().Get("item_id"); v != "" {
+		itemID, err = strconv.Atoi(v)
+		if err != nil || itemID < 1 {
+			writeError(w, r, newAPIError(http.StatusBadRequest, "invalid item ID"))
+			return
+		}
+	}
+
+	decisions, err := s.moderationRepo.ListDecisions(r.Context(), itemID, limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get moderation decisions: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Decisions []ModerationDecision `json:"decisions"`
+		Limit     int                  `json:"limit"`
+		Offset    int                  `json:"offset"`
+	}{Decisions: decisions, Limit: limit, Offset: offset})
+}
+
+// ApproveModerationReview is a handler to list a quarantined item for POST /admin/moderation/reviews/{review_id}/approve .
+func (s *Handlers) ApproveModerationReview(w http.ResponseWriter, r *http.Request) {
+	s.resolveModerationReview(w, r, ReviewStatusApproved)
+}
+
+// RejectModerationReview is a handler to reject a quarantined item for POST /admin/moderation/reviews/{review_id}/reject .
+func (s *Handlers) RejectModerationReview(w http.ResponseWriter, r *http.Request) {
+	s.resolveModerationReview(w, r, ReviewStatusRejected)
+}
+
+// resolveModerationReview resolves the review and tells the seller the result.
+func (s *Handlers) resolveModerationReview(w http.ResponseWriter, r *http.Request, status ReviewStatus) {
+	ctx := r.Context()
+
+	adminID, err := s.parseAdminID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	reviewID, err := strconv.Atoi(r.PathValue("review_id"))
+	if err != nil || reviewID < 1 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, "invalid review ID"))
+		return
+	}
+
+	review, err := s.moderationRepo.GetReview(ctx, reviewID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get moderation review: %w", err))
+		return
+	}
+
+	review.Status = status
+	review.ReviewerID = adminID
+	review.Note = strings.TrimSpace(r.FormValue("note"))
+	itemStatus := ItemStatusOnSale
+	if status == ReviewStatusRejected {
+		itemStatus = ItemStatusRejected
+	}
+	if err := s.moderationRepo.Resolve(ctx, review, itemStatus); err != nil {
+		writeError(w, r, fmt.Errorf("failed to resolve moderation review: %w", err))
+		return
+	}
+
+	// notifications are best effort and must not fail the review
+	if err := s.notifyModerationResult(ctx, review); err != nil {
+		slog.ErrorContext(ctx, "failed to notify moderation result: ", "error", err)
+	}
+
+	writeJSON(w, http.StatusOK, review)
+}
+
+// notifyModerationResult tells the seller the result of the review,
+// and the saved searches matching the item when it gets listed.
+func (s *Handlers) notifyModerationResult(ctx context.Context, review *ModerationReview) error {
+	item, err := s.itemRepo.GetByID(ctx, review.ItemID)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your item has been approved: %s", item.Name)
+	if review.Status == ReviewStatusRejected {
+		message = fmt.Sprintf("Your item has been rejected: %s", item.Name)
+		if review.Note != "" {
+			message += " (" + review.Note + ")"
+		}
+	}
+	err = s.notifier.Notify(ctx, &Notification{
+		UserID:  item.SellerID,
+		Type:    NotificationModerationResult,
+		ItemID:  item.ID,
+		Message: message,
+	})
+	if err != nil || review.Status != ReviewStatusApproved {
+		return err
+	}
+	return s.notifyPublished(ctx, item)
+}
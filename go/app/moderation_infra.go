@@ -0,0 +1,361 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errModerationRuleNotFound   = errors.New("moderation rule not found")
+	errModerationReviewNotFound = errors.New("moderation review not found")
+	errReviewAlreadyResolved    = errors.New("moderation review is already resolved")
+)
+
+// ModerationRule flags the items containing a banned word.
+// A rule with a category applies only to the items of that category,
+// and a rule for a category without a word prohibits the whole category.
+type ModerationRule struct {
+	ID         int `db:"id" json:"id"`
+	CategoryID int `db:"category_id" json:"category_id,omitempty"`
+	// Word is stored normalized with normalizeForModeration.
+	Word   string `db:"word" json:"word,omitempty"`
+	Reason string `db:"reason" json:"reason"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ReviewStatus is the state of a moderation review.
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusRejected ReviewStatus = "rejected"
+)
+
+// ModerationReview is a flagged item waiting for or resolved by an admin.
+type ModerationReview struct {
+	ID      int          `db:"id" json:"id"`
+	ItemID  int          `db:"item_id" json:"item_id"`
+	Reasons []string     `db:"reasons" json:"reasons"`
+	Status  ReviewStatus `db:"status" json:"status"`
+	// ReviewerID is the admin who resolved the review, or 0 while it is pending.
+	ReviewerID int    `db:"reviewer_id" json:"reviewer_id,omitempty"`
+	Note       string `db:"note" json:"note,omitempty"`
+
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	ReviewedAt *time.Time `db:"reviewed_at" json:"reviewed_at,omitempty"`
+}
+
+// ModerationAction is what a moderation decision did to an item.
+type ModerationAction string
+
+const (
+	ModerationActionApprove ModerationAction = "approve"
+	ModerationActionReject  ModerationAction = "reject"
+	// ModerationActionAutoHide is an item hidden by the system after many reports.
+	ModerationActionAutoHide ModerationAction = "auto_hide"
+	// ModerationActionDismissReports keeps an item listed after its reports are reviewed.
+	ModerationActionDismissReports ModerationAction = "dismiss_reports"
+	// ModerationActionRemove takes down an item after its reports are reviewed.
+	ModerationActionRemove ModerationAction = "remove"
+)
+
+// ModerationDecision is an entry of the audit trail of the moderation decisions.
+type ModerationDecision struct {
+	ID     int `db:"id" json:"id"`
+	ItemID int `db:"item_id" json:"item_id"`
+	// ModeratorID is the admin who made the decision, or 0 if the system did.
+	ModeratorID int              `db:"moderator_id" json:"moderator_id"`
+	Action      ModerationAction `db:"action" json:"action"`
+	Note        string           `db:"note" json:"note,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ModerationRepository is an interface to manage the moderation rules and the review queue.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type ModerationRepository interface {
+	InsertRule(ctx context.Context, rule *ModerationRule) error
+	// ListRules returns every rule, or the rules applying to the category if categoryID is not 0.
+	ListRules(ctx context.Context, categoryID int) ([]ModerationRule, error)
+	// DeleteRule deletes the rule and returns errModerationRuleNotFound if there is none.
+	DeleteRule(ctx context.Context, ruleID int) error
+	// Quarantine hides the item and queues a review for it.
+	Quarantine(ctx context.Context, itemID int, reasons []string) (*ModerationReview, error)
+	GetReview(ctx context.Context, reviewID int) (*ModerationReview, error)
+	// ListReviews returns the reviews with the status, oldest first.
+	ListReviews(ctx context.Context, status ReviewStatus, limit, offset int) ([]ModerationReview, error)
+	// Resolve approves or rejects the pending review and sets the item to the status.
+	// It returns errReviewAlreadyResolved if the review is not pending.
+	Resolve(ctx context.Context, review *ModerationReview, itemStatus ItemStatus) error
+	// ListDecisions returns the audit trail, newest first, only of the item if itemID is not 0.
+	ListDecisions(ctx context.Context, itemID, limit, offset int) ([]ModerationDecision, error)
+}
+
+// moderationRepository is an implementation of ModerationRepository
+type moderationRepository struct {
+	db *sql.DB
+	// index is refreshed as the items are quarantined and released, if it is not nil.
+	index *SimilarityIndex
+}
+
+// NewModerationRepository creates a new moderationRepository keeping the index up to date.
+func NewModerationRepository(database *sql.DB, index *SimilarityIndex) ModerationRepository {
+	return &moderationRepository{db: database, index: index}
+}
+
+func (m *moderationRepository) InsertRule(ctx context.Context, rule *ModerationRule) error {
+	rule.CreatedAt = time.Now().UTC()
+	res, err := m.db.ExecContext(ctx, "INSERT INTO moderation_rules (category_id, word, reason, created_at) VALUES (?, ?, ?, ?)",
+		rule.CategoryID, rule.Word, rule.Reason, rule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert moderation rule: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get moderation rule id: %w", err)
+	}
+	rule.ID = int(id)
+	return nil
+}
+
+func (m *moderationRepository) ListRules(ctx context.Context, categoryID int) ([]ModerationRule, error) {
+	query := "SELECT id, category_id, word, reason, created_at FROM moderation_rules"
+	var args []any
+	if categoryID != 0 {
+		query += " WHERE category_id = 0 OR category_id = ?"
+		args = append(args, categoryID)
+	}
+	query += " ORDER BY id"
+
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []ModerationRule{}
+	for rows.Next() {
+		var rule ModerationRule
+		if err := rows.Scan(&rule.ID, &rule.CategoryID, &rule.Word, &rule.Reason, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan moderation rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate moderation rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (m *moderationRepository) DeleteRule(ctx context.Context, ruleID int) error {
+	res, err := m.db.ExecContext(ctx, "DELETE FROM moderation_rules WHERE id = ?", ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete moderation rule: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete moderation rule: %w", err)
+	}
+	if n == 0 {
+		return errModerationRuleNotFound
+	}
+	return nil
+}
+
+func (m *moderationRepository) Quarantine(ctx context.Context, itemID int, reasons []string) (*ModerationReview, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "UPDATE items SET status = ? WHERE id = ?", ItemStatusQuarantined, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quarantine item: %w", err)
+	}
+	review, err := insertModerationReview(ctx, tx, itemID, reasons)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	m.index.Remove(itemID)
+	return review, nil
+}
+
+// insertModerationReview opens a pending review of the item within the transaction quarantining it.
+func insertModerationReview(ctx context.Context, tx *sql.Tx, itemID int, reasons []string) (*ModerationReview, error) {
+	encoded, err := json.Marshal(reasons)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode reasons: %w", err)
+	}
+
+	review := &ModerationReview{ItemID: itemID, Reasons: reasons, Status: ReviewStatusPending, CreatedAt: time.Now().UTC()}
+	res, err := tx.ExecContext(ctx, "INSERT INTO moderation_reviews (item_id, reasons, status, created_at) VALUES (?, ?, ?, ?)",
+		review.ItemID, string(encoded), review.Status, review.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert moderation review: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation review id: %w", err)
+	}
+	review.ID = int(id)
+	return review, nil
+}
+
+const moderationReviewColumns = "id, item_id, reasons, status, reviewer_id, note, created_at, reviewed_at"
+
+func scanModerationReview(row interface{ Scan(dest ...any) error }, review *ModerationReview) error {
+	var (
+		reasons    string
+		reviewedAt sql.NullTime
+	)
+	err := row.Scan(&review.ID, &review.ItemID, &reasons, &review.Status, &review.ReviewerID, &review.Note, &review.CreatedAt, &reviewedAt)
+	if err != nil {
+		return err
+	}
+	if reviewedAt.Valid {
+		review.ReviewedAt = &reviewedAt.Time
+	}
+	return json.Unmarshal([]byte(reasons), &review.Reasons)
+}
+
+func (m *moderationRepository) GetReview(ctx context.Context, reviewID int) (*ModerationReview, error) {
+	var review ModerationReview
+	err := scanModerationReview(m.db.QueryRowContext(ctx, "SELECT "+moderationReviewColumns+" FROM moderation_reviews WHERE id = ?", reviewID), &review)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errModerationReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to get moderation review: %w", err)
+	}
+	return &review, nil
+}
+
+func (m *moderationRepository) ListReviews(ctx context.Context, status ReviewStatus, limit, offset int) ([]ModerationReview, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT "+moderationReviewColumns+" FROM moderation_reviews WHERE status = ? ORDER BY id LIMIT ? OFFSET ?",
+		status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []ModerationReview{}
+	for rows.Next() {
+		var review ModerationReview
+		if err := scanModerationReview(rows, &review); err != nil {
+			return nil, fmt.Errorf("failed to scan moderation review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate moderation reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+func (m *moderationRepository) Resolve(ctx context.Context, review *ModerationReview, itemStatus ItemStatus) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reviewedAt := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, "UPDATE moderation_reviews SET status = ?, reviewer_id = ?, note = ?, reviewed_at = ? WHERE id = ? AND status = ?",
+		review.Status, review.ReviewerID, review.Note, reviewedAt, review.ID, ReviewStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to resolve moderation review: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to resolve moderation review: %w", err)
+	}
+	if n == 0 {
+		return errReviewAlreadyResolved
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE items SET status = ? WHERE id = ? AND status = ?", itemStatus, review.ItemID, ItemStatusQuarantined)
+	if err != nil {
+		return fmt.Errorf("failed to update item status: %w", err)
+	}
+	item, err := getItemTx(ctx, tx, review.ItemID)
+	if err != nil {
+		return err
+	}
+
+	action := ModerationActionApprove
+	if review.Status == ReviewStatusRejected {
+		action = ModerationActionReject
+	}
+	decision := &ModerationDecision{ItemID: review.ItemID, ModeratorID: review.ReviewerID, Action: action, Note: review.Note}
+	if err := insertModerationDecision(ctx, tx, decision); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	m.index.Sync(item)
+	review.ReviewedAt = &reviewedAt
+	return nil
+}
+
+// insertModerationDecision records the decision in the audit trail within the transaction of the decision itself.
+func insertModerationDecision(ctx context.Context, tx *sql.Tx, decision *ModerationDecision) error {
+	decision.CreatedAt = time.Now().UTC()
+	res, err := tx.ExecContext(ctx, "INSERT INTO moderation_decisions (item_id, moderator_id, action, note, created_at) VALUES (?, ?, ?, ?, ?)",
+		decision.ItemID, decision.ModeratorID, decision.Action, decision.Note, decision.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert moderation decision: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get moderation decision id: %w", err)
+	}
+	decision.ID = int(id)
+	return nil
+}
+
+func (m *moderationRepository) ListDecisions(ctx context.Context, itemID, limit, offset int) ([]ModerationDecision, error) {
+	query := "SELECT id, item_id, moderator_id, action, note, created_at FROM moderation_decisions"
+	var args []any
+	if itemID != 0 {
+		query += " WHERE item_id = ?"
+		args = append(args, itemID)
+	}
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation decisions: %w", err)
+	}
+	defer rows.Close()
+
+	decisions := []ModerationDecision{}
+	for rows.Next() {
+		var d ModerationDecision
+		if err := rows.Scan(&d.ID, &d.ItemID, &d.ModeratorID, &d.Action, &d.Note, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan moderation decision: %w", err)
+		}
+		decisions = append(decisions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate moderation decisions: %w", err)
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,208 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNormalizeForModeration(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		in   string
+		want string
+	}{
+		"full-width latin":   {in: "ＲＯＬＥＸ", want: "rolex"},
+		"half-width kana":    {in: "ｺﾋﾟｰ品", want: "こぴー品"},
+		"katakana":           {in: "コピー品", want: "こぴー品"},
+		"spaces and symbols": {in: "コ ピ ー・品！", want: "こぴー品"},
+		"mixed":              {in: "Nintendo Switch 本体", want: "nintendoswitch本体"},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := normalizeForModeration(tt.in); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckModeration(t *testing.T) {
+	t.Parallel()
+
+	rules := []ModerationRule{
+		{ID: 1, Word: normalizeForModeration("コピー品"), Reason: "counterfeit"},
+		{ID: 2, CategoryID: 3, Reason: "prohibited category"},
+		{ID: 3, CategoryID: 2, Word: normalizeForModeration("処方薬"), Reason: "medicine"},
+	}
+
+	cases := map[string]struct {
+		item *Item
+		want []string
+	}{
+		"ok: clean item": {
+			item: &Item{Name: "jacket", CategoryID: 2},
+		},
+		"ok: word of another category": {
+			item: &Item{Name: "処方薬の本", CategoryID: 1},
+		},
+		"ng: banned word written in half-width kana": {
+			item: &Item{Name: "ﾌﾞﾗﾝﾄﾞ ｺﾋﾟｰ品", CategoryID: 2},
+			want: []string{"counterfeit"},
+		},
+		"ng: banned word in an attribute": {
+			item: &Item{Name: "watch", CategoryID: 2, Attributes: map[string]string{"brand": "コピー品"}},
+			want: []string{"counterfeit"},
+		},
+		"ng: prohibited category": {
+			item: &Item{Name: "knife", CategoryID: 3},
+			want: []string{"prohibited category"},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockMR := NewMockModerationRepository(ctrl)
+			mockMR.EXPECT().ListRules(gomock.Any(), tt.item.CategoryID).Return(rules, nil)
+			h := &Handlers{moderationRepo: mockMR}
+
+			got, err := h.checkModeration(context.Background(), tt.item)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected reasons (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApproveModerationReview(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID string
+		// injector is used to inject the expected calls to the mock
+		injector func(m *MockModerationRepository, i *MockItemRepository, ss *MockSavedSearchRepository)
+		wants
+	}{
+		"ok: approved": {
+			userID: "9",
+			injector: func(m *MockModerationRepository, i *MockItemRepository, ss *MockSavedSearchRepository) {
+				m.EXPECT().GetReview(gomock.Any(), 5).Return(&ModerationReview{ID: 5, ItemID: 1, Status: ReviewStatusPending}, nil)
+				m.EXPECT().Resolve(gomock.Any(), &ModerationReview{ID: 5, ItemID: 1, Status: ReviewStatusApproved, ReviewerID: 9}, ItemStatusOnSale).Return(nil)
+				item := &Item{ID: 1, Name: "jacket", CategoryID: 2, SellerID: 1, Status: ItemStatusOnSale}
+				i.EXPECT().GetByID(gomock.Any(), 1).Return(item, nil)
+				i.EXPECT().GetCategoryName(gomock.Any(), 2).Return("fashion", nil)
+				ss.EXPECT().ListMatching(gomock.Any(), item, "fashion").Return(nil, nil)
+			},
+			wants: wants{code: http.StatusOK},
+		},
+		"ng: already resolved": {
+			userID: "9",
+			injector: func(m *MockModerationRepository, i *MockItemRepository, ss *MockSavedSearchRepository) {
+				m.EXPECT().GetReview(gomock.Any(), 5).Return(&ModerationReview{ID: 5, ItemID: 1, Status: ReviewStatusRejected}, nil)
+				m.EXPECT().Resolve(gomock.Any(), gomock.Any(), ItemStatusOnSale).Return(errReviewAlreadyResolved)
+			},
+			wants: wants{code: http.StatusConflict},
+		},
+		"ng: not an admin": {
+			userID:   "1",
+			injector: func(m *MockModerationRepository, i *MockItemRepository, ss *MockSavedSearchRepository) {},
+			wants:    wants{code: http.StatusForbidden},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockMR := NewMockModerationRepository(ctrl)
+			mockIR := NewMockItemRepository(ctrl)
+			mockSSR := NewMockSavedSearchRepository(ctrl)
+			tt.injector(mockMR, mockIR, mockSSR)
+			notifier := &recordingNotifier{}
+			h := &Handlers{
+				itemRepo:        mockIR,
+				savedSearchRepo: mockSSR,
+				moderationRepo:  mockMR,
+				notifier:        notifier,
+				adminIDs:        map[int]bool{9: true},
+			}
+
+			req := httptest.NewRequest("POST", "/admin/moderation/reviews/5/approve", nil)
+			req.SetPathValue("review_id", "5")
+			req.Header.Set(userIDHeader, tt.userID)
+
+			rr := httptest.NewRecorder()
+			h.ApproveModerationReview(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+			if rr.Code == http.StatusOK && (len(notifier.notifications) != 1 || notifier.notifications[0].UserID != 1) {
+				t.Errorf("expected the seller to be notified, got %+v", notifier.notifications)
+			}
+		})
+	}
+}
+
+func TestItemRepositoryUpdateQuarantined(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	index := NewSimilarityIndex()
+	repo := &itemRepository{db: db, index: index}
+	ctx := context.Background()
+	item := &Item{Name: "jacket", CategoryID: 1, Image: "a.jpg", SellerID: 1, Price: 1000, Status: ItemStatusOnSale}
+	if err := repo.Insert(ctx, item); err != nil {
+		t.Fatalf("failed to insert item: %v", err)
+	}
+
+	item.Name = "fake jacket"
+	review, err := repo.UpdateQuarantined(ctx, item, []string{"counterfeit"})
+	if err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+	if review.ItemID != item.ID || review.Status != ReviewStatusPending {
+		t.Errorf("unexpected review: %+v", review)
+	}
+
+	got, err := repo.GetByID(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if got.Name != "fake jacket" || got.Status != ItemStatusQuarantined {
+		t.Errorf("expected the renamed item to be quarantined, got %+v", got)
+	}
+	if ids := index.Similar(&Item{Name: "jacket", CategoryID: 1}, time.Now(), 10); len(ids) != 0 {
+		t.Errorf("expected the quarantined item to leave the index, got %v", ids)
+	}
+}
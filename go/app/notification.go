@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Notifier delivers notifications to users.
+// Channels such as email or push can be added by implementing it and listing them in Notifiers.
+type Notifier interface {
+	Notify(ctx context.Context, notification *Notification) error
+}
+
+// inAppNotifier stores notifications so that users can see them via GET /notifications .
+type inAppNotifier struct {
+	notificationRepo NotificationRepository
+}
+
+// NewInAppNotifier creates a Notifier storing notifications in the repository.
+func NewInAppNotifier(notificationRepo NotificationRepository) Notifier {
+	return &inAppNotifier{notificationRepo: notificationRepo}
+}
+
+func (n *inAppNotifier) Notify(ctx context.Context, notification *Notification) error {
+	return n.notificationRepo.Insert(ctx, notification)
+}
+
+// Notifiers delivers a notification through every notifier in it.
+// A failing notifier does not prevent the others from being tried.
+type Notifiers []Notifier
+
+func (ns Notifiers) Notify(ctx context.Context, notification *Notification) error {
+	var errs []error
+	for _, n := range ns {
+		if err := n.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetNotifications is a handler to return the notifications of the user for GET /notifications .
+func (s *Handlers) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	notifications, err := s.notificationRepo.ListByUserID(r.Context(), userID, limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get notifications: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Notifications []Notification `json:"notifications"`
+		Limit         int            `json:"limit"`
+		Offset        int            `json:"offset"`
+	}{Notifications: notifications, Limit: limit, Offset: offset})
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// NotificationType is the event a notification is about.
+type NotificationType string
+
+const (
+	// NotificationSavedSearchMatch is sent when a new item matches a saved search.
+	NotificationSavedSearchMatch NotificationType = "saved_search_match"
+	// NotificationPriceDrop is sent to the watchers of an item when its price is lowered.
+	NotificationPriceDrop NotificationType = "price_drop"
+	// NotificationModerationResult is sent to the seller when the review of a flagged item is resolved.
+	NotificationModerationResult NotificationType = "moderation_result"
+)
+
+type Notification struct {
+	ID      int              `db:"id" json:"id"`
+	UserID  int              `db:"user_id" json:"user_id"`
+	Type    NotificationType `db:"type" json:"type"`
+	ItemID  int              `db:"item_id" json:"item_id"`
+	Message string           `db:"message" json:"message"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// NotificationRepository is an interface to manage the notifications shown in the app.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type NotificationRepository interface {
+	Insert(ctx context.Context, notification *Notification) error
+	// ListByUserID returns the notifications of the user, newest first.
+	ListByUserID(ctx context.Context, userID, limit, offset int) ([]Notification, error)
+}
+
+// notificationRepository is an implementation of NotificationRepository
+type notificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new notificationRepository.
+func NewNotificationRepository(database *sql.DB) NotificationRepository {
+	return &notificationRepository{db: database}
+}
+
+func (n *notificationRepository) Insert(ctx context.Context, notification *Notification) error {
+	notification.CreatedAt = time.Now().UTC()
+	res, err := n.db.ExecContext(ctx, "INSERT INTO notifications (user_id, type, item_id, message, created_at) VALUES (?, ?, ?, ?, ?)",
+		notification.UserID, notification.Type, notification.ItemID, notification.Message, notification.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert notification: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get notification id: %w", err)
+	}
+	notification.ID = int(id)
+	return nil
+}
+
+func (n *notificationRepository) ListByUserID(ctx context.Context, userID, limit, offset int) ([]Notification, error) {
+	rows, err := n.db.QueryContext(ctx, `
+		SELECT id, user_id, type, item_id, message, created_at FROM notifications
+		WHERE user_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var nt Notification
+		if err := rows.Scan(&nt.ID, &nt.UserID, &nt.Type, &nt.ItemID, &nt.Message, &nt.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, nt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+
+	return notifications, nil
+}
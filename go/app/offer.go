@@ -0,0 +1,290 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// offerTTL is how long an offer or a counter offer waits for a response.
+	offerTTL = 48 * time.Hour
+	// acceptedOfferTTL is how long an accepted offer locks the item for the buyer.
+	acceptedOfferTTL = 24 * time.Hour
+)
+
+// parsePrice parses a positive price from the form value with the given key.
+func parsePrice(r *http.Request, key string) (int, error) {
+	var v validator
+	s := r.FormValue(key)
+	if !v.required(key, s) {
+		return 0, v.err()
+	}
+	price := v.intRange(key, s, 1, noLimit)
+	return price, v.err()
+}
+
+// parseOfferID parses the offer ID from the path.
+func parseOfferID(r *http.Request) (int, error) {
+	offerID, err := strconv.Atoi(r.PathValue("offer_id"))
+	if err != nil || offerID < 1 {
+		return 0, errors.New("invalid offer ID")
+	}
+	return offerID, nil
+}
+
+// AddOffer is a handler for a buyer to propose a price for POST /items/{item_id}/offers .
+func (s *Handlers) AddOffer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	price, err := parsePrice(r, "price")
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+	if item.SellerID == userID {
+		writeError(w, r, newAPIError(http.StatusForbidden, "sellers cannot make offers on their own items"))
+		return
+	}
+	if item.Status != ItemStatusOnSale {
+		writeError(w, r, errItemNotOnSale)
+		return
+	}
+	if item.Price > 0 && price >= item.Price {
+		writeError(w, r, newValidationError("price", "price must be lower than the item price"))
+		return
+	}
+
+	now := time.Now().UTC()
+	offers, err := s.listOffers(ctx, itemID, now)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get offers: %w", err))
+		return
+	}
+	for _, o := range offers {
+		if o.Status == OfferStatusAccepted {
+			writeError(w, r, newAPIError(http.StatusConflict, "item is reserved by an accepted offer"))
+			return
+		}
+		if o.BuyerID == userID && o.IsOpen() {
+			writeError(w, r, newAPIError(http.StatusConflict, "an open offer already exists"))
+			return
+		}
+	}
+
+	offer := &Offer{
+		ItemID:    itemID,
+		BuyerID:   userID,
+		Price:     price,
+		Status:    OfferStatusPending,
+		ExpiresAt: now.Add(offerTTL),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.offerRepo.Insert(ctx, offer); err != nil {
+		writeError(w, r, fmt.Errorf("failed to store offer: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, offer)
+}
+
+// listOffers expires stale offers and returns the remaining ones on the item.
+func (s *Handlers) listOffers(ctx context.Context, itemID int, now time.Time) ([]Offer, error) {
+	if err := s.offerRepo.ExpireStale(ctx, now); err != nil {
+		return nil, err
+	}
+	return s.offerRepo.ListByItemID(ctx, itemID)
+}
+
+// GetOffers is a handler to return the offers on an item for GET /items/{item_id}/offers .
+// The seller sees every offer while the others only see their own.
+func (s *Handlers) GetOffers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+
+	offers, err := s.listOffers(ctx, itemID, time.Now().UTC())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get offers: %w", err))
+		return
+	}
+
+	visible := []Offer{}
+	for _, o := range offers {
+		if item.SellerID == userID || o.BuyerID == userID {
+			visible = append(visible, o)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Offers []Offer `json:"offers"`
+	}{Offers: visible})
+}
+
+// respondableOffer loads the offer in the path and checks that the user is the one who must respond to it:
+// the seller for a pending offer and the buyer for a countered one.
+// It writes the error response and returns nil when the offer cannot be responded to.
+func (s *Handlers) respondableOffer(w http.ResponseWriter, r *http.Request, now time.Time) (*Offer, *Item) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return nil, nil
+	}
+	offerID, err := parseOfferID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return nil, nil
+	}
+
+	if err := s.offerRepo.ExpireStale(ctx, now); err != nil {
+		writeError(w, r, fmt.Errorf("failed to expire offers: %w", err))
+		return nil, nil
+	}
+	offer, err := s.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get offer: %w", err))
+		return nil, nil
+	}
+	item, err := s.itemRepo.GetByID(ctx, offer.ItemID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return nil, nil
+	}
+
+	if userID != item.SellerID && userID != offer.BuyerID {
+		writeError(w, r, errOfferNotFound)
+		return nil, nil
+	}
+	if !offer.IsOpen() {
+		writeError(w, r, newAPIError(http.StatusConflict, "offer is already "+string(offer.Status)))
+		return nil, nil
+	}
+	if offer.Status == OfferStatusPending && userID != item.SellerID ||
+		offer.Status == OfferStatusCountered && userID != offer.BuyerID {
+		writeError(w, r, newAPIError(http.StatusForbidden, "waiting for the other party to respond"))
+		return nil, nil
+	}
+
+	return offer, item
+}
+
+// AcceptOffer is a handler to accept an offer for POST /offers/{offer_id}/accept .
+// An accepted offer locks the item so that only the buyer can purchase it at the agreed price.
+func (s *Handlers) AcceptOffer(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	offer, item := s.respondableOffer(w, r, now)
+	if offer == nil {
+		return
+	}
+	if item.Status != ItemStatusOnSale {
+		writeError(w, r, errItemNotOnSale)
+		return
+	}
+
+	offer.Status = OfferStatusAccepted
+	offer.ExpiresAt = now.Add(acceptedOfferTTL)
+	offer.UpdatedAt = now
+	if err := s.offerRepo.Accept(r.Context(), offer); err != nil {
+		writeError(w, r, fmt.Errorf("failed to accept offer: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, offer)
+}
+
+// RejectOffer is a handler to reject an offer for POST /offers/{offer_id}/reject .
+func (s *Handlers) RejectOffer(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	offer, _ := s.respondableOffer(w, r, now)
+	if offer == nil {
+		return
+	}
+
+	offer.Status = OfferStatusRejected
+	offer.UpdatedAt = now
+	if err := s.offerRepo.Update(r.Context(), offer); err != nil {
+		writeError(w, r, fmt.Errorf("failed to reject offer: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, offer)
+}
+
+// CounterOffer is a handler for the seller to propose another price for POST /offers/{offer_id}/counter .
+func (s *Handlers) CounterOffer(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	offer, item := s.respondableOffer(w, r, now)
+	if offer == nil {
+		return
+	}
+	if offer.Status != OfferStatusPending {
+		writeError(w, r, newAPIError(http.StatusConflict, "only pending offers can be countered"))
+		return
+	}
+
+	price, err := parsePrice(r, "price")
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	if price <= offer.Price || item.Price > 0 && price >= item.Price {
+		writeError(w, r, newValidationError("price", "price must be between the offered price and the item price"))
+		return
+	}
+
+	offer.CounterPrice = price
+	offer.Status = OfferStatusCountered
+	offer.ExpiresAt = now.Add(offerTTL)
+	offer.UpdatedAt = now
+	if err := s.offerRepo.Update(r.Context(), offer); err != nil {
+		writeError(w, r, fmt.Errorf("failed to counter offer: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, offer)
+}
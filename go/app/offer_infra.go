@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errOfferNotFound = errors.New("offer not found")
+	// errOfferNotOpen is returned when an offer has been responded to or has expired in the meantime.
+	errOfferNotOpen = errors.New("offer is no longer open")
+	// errItemReserved is returned when another buyer's accepted offer locks the item.
+	errItemReserved = errors.New("item is reserved for another buyer")
+)
+
+// OfferStatus is the state of a price offer in the negotiation.
+type OfferStatus string
+
+const (
+	// OfferStatusPending is waiting for the seller to respond.
+	OfferStatusPending OfferStatus = "pending"
+	// OfferStatusCountered is waiting for the buyer to respond to the counter price.
+	OfferStatusCountered OfferStatus = "countered"
+	// OfferStatusAccepted locks the item at the agreed price until it expires.
+	OfferStatusAccepted OfferStatus = "accepted"
+	OfferStatusRejected OfferStatus = "rejected"
+	OfferStatusExpired  OfferStatus = "expired"
+	// OfferStatusCompleted means the item was purchased through the offer.
+	OfferStatusCompleted OfferStatus = "completed"
+)
+
+type Offer struct {
+	ID           int         `db:"id" json:"id"`
+	ItemID       int         `db:"item_id" json:"item_id"`
+	BuyerID      int         `db:"buyer_id" json:"buyer_id"`
+	Price        int         `db:"price" json:"price"`
+	CounterPrice int         `db:"counter_price" json:"counter_price,omitempty"`
+	Status       OfferStatus `db:"status" json:"status"`
+	ExpiresAt    time.Time   `db:"expires_at" json:"expires_at"`
+	CreatedAt    time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time   `db:"updated_at" json:"updated_at"`
+}
+
+// AgreedPrice returns the price both parties agree on once the offer is accepted.
+func (o *Offer) AgreedPrice() int {
+	if o.CounterPrice > 0 {
+		return o.CounterPrice
+	}
+	return o.Price
+}
+
+// IsOpen reports whether the offer is still waiting for a response.
+func (o *Offer) IsOpen() bool {
+	return o.Status == OfferStatusPending || o.Status == OfferStatusCountered
+}
+
+// OfferRepository is an interface to manage price offers on items.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type OfferRepository interface {
+	Insert(ctx context.Context, offer *Offer) error
+	GetByID(ctx context.Context, offerID int) (*Offer, error)
+	ListByItemID(ctx context.Context, itemID int) ([]Offer, error)
+	// Update updates an open offer, such as to reject or counter it.
+	// It returns errOfferNotOpen if the offer is no longer pending or countered.
+	Update(ctx context.Context, offer *Offer) error
+	// Accept accepts the offer and rejects the other open offers on the same item.
+	// It returns errOfferNotOpen if the offer is no longer pending or countered.
+	Accept(ctx context.Context, offer *Offer) error
+	// ExpireStale marks open and accepted offers past their expiry as expired.
+	ExpireStale(ctx context.Context, now time.Time) error
+}
+
+// offerRepository is an implementation of OfferRepository
+type offerRepository struct {
+	db *sql.DB
+}
+
+// NewOfferRepository creates a new offerRepository.
+func NewOfferRepository(database *sql.DB) OfferRepository {
+	return &offerRepository{db: database}
+}
+
+const offerColumns = "id, item_id, buyer_id, price, counter_price, status, expires_at, created_at, updated_at"
+
+func scanOffer(row interface{ Scan(dest ...any) error }, offer *Offer) error {
+	return row.Scan(&offer.ID, &offer.ItemID, &offer.BuyerID, &offer.Price, &offer.CounterPrice,
+		&offer.Status, &offer.ExpiresAt, &offer.CreatedAt, &offer.UpdatedAt)
+}
+
+func (o *offerRepository) Insert(ctx context.Context, offer *Offer) error {
+	res, err := o.db.ExecContext(ctx, `
+		INSERT INTO offers (item_id, buyer_id, price, counter_price, status, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		offer.ItemID, offer.BuyerID, offer.Price, offer.CounterPrice, offer.Status, offer.ExpiresAt, offer.CreatedAt, offer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert offer: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get offer id: %w", err)
+	}
+	offer.ID = int(id)
+	return nil
+}
+
+func (o *offerRepository) GetByID(ctx context.Context, offerID int) (*Offer, error) {
+	var offer Offer
+	err := scanOffer(o.db.QueryRowContext(ctx, "SELECT "+offerColumns+" FROM offers WHERE id = ?", offerID), &offer)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errOfferNotFound
+		}
+		return nil, fmt.Errorf("failed to query offer: %w", err)
+	}
+	return &offer, nil
+}
+
+// ListByItemID returns the offers on an item, newest first.
+func (o *offerRepository) ListByItemID(ctx context.Context, itemID int) ([]Offer, error) {
+	rows, err := o.db.QueryContext(ctx, "SELECT "+offerColumns+" FROM offers WHERE item_id = ? ORDER BY id DESC", itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get offers: %w", err)
+	}
+	defer rows.Close()
+
+	offers := []Offer{}
+	for rows.Next() {
+		var offer Offer
+		if err := scanOffer(rows, &offer); err != nil {
+			return nil, fmt.Errorf("failed to scan offer: %w", err)
+		}
+		offers = append(offers, offer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate offers: %w", err)
+	}
+
+	return offers, nil
+}
+
+func (o *offerRepository) Update(ctx context.Context, offer *Offer) error {
+	res, err := o.db.ExecContext(ctx, "UPDATE offers SET counter_price = ?, status = ?, expires_at = ?, updated_at = ? WHERE id = ? AND status IN (?, ?)",
+		offer.CounterPrice, offer.Status, offer.ExpiresAt, offer.UpdatedAt, offer.ID, OfferStatusPending, OfferStatusCountered)
+	if err != nil {
+		return fmt.Errorf("failed to update offer: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update offer: %w", err)
+	}
+	if n == 0 {
+		return errOfferNotOpen
+	}
+	return nil
+}
+
+func (o *offerRepository) Accept(ctx context.Context, offer *Offer) error {
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE offers SET status = ?, expires_at = ?, updated_at = ? WHERE id = ? AND status IN (?, ?)",
+		OfferStatusAccepted, offer.ExpiresAt, offer.UpdatedAt, offer.ID, OfferStatusPending, OfferStatusCountered)
+	if err != nil {
+		return fmt.Errorf("failed to accept offer: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to accept offer: %w", err)
+	}
+	if n == 0 {
+		return errOfferNotOpen
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE offers SET status = ?, updated_at = ? WHERE item_id = ? AND id <> ? AND status IN (?, ?)",
+		OfferStatusRejected, offer.UpdatedAt, offer.ItemID, offer.ID, OfferStatusPending, OfferStatusCountered)
+	if err != nil {
+		return fmt.Errorf("failed to reject other offers: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (o *offerRepository) ExpireStale(ctx context.Context, now time.Time) error {
+	_, err := o.db.ExecContext(ctx, "UPDATE offers SET status = ?, updated_at = ? WHERE status IN (?, ?, ?) AND expires_at <= ?",
+		OfferStatusExpired, now, OfferStatusPending, OfferStatusCountered, OfferStatusAccepted, now)
+	if err != nil {
+		return fmt.Errorf("failed to expire offers: %w", err)
+	}
+	return nil
+}
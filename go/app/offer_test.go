@@ -0,0 +1,309 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddOffer(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID   string
+		price    string
+		injector func(ir *MockItemRepository, or *MockOfferRepository)
+		wants
+	}{
+		"ok: offer created": {
+			userID: "2",
+			price:  "800",
+			injector: func(ir *MockItemRepository, or *MockOfferRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Price: 1000, Status: ItemStatusOnSale}, nil).Times(1)
+				or.EXPECT().ExpireStale(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				or.EXPECT().ListByItemID(gomock.Any(), 1).Return([]Offer{}, nil).Times(1)
+				or.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			wants: wants{code: http.StatusCreated},
+		},
+		"ng: price is not lower than the item price": {
+			userID: "2",
+			price:  "1000",
+			injector: func(ir *MockItemRepository, or *MockOfferRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Price: 1000, Status: ItemStatusOnSale}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusUnprocessableEntity},
+		},
+		"ng: offer on own item": {
+			userID: "1",
+			price:  "800",
+			injector: func(ir *MockItemRepository, or *MockOfferRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Price: 1000, Status: ItemStatusOnSale}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusForbidden},
+		},
+		"ng: draft of another user": {
+			userID: "2",
+			price:  "800",
+			injector: func(ir *MockItemRepository, or *MockOfferRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Price: 1000, Status: ItemStatusDraft}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+		"ng: item is reserved": {
+			userID: "2",
+			price:  "800",
+			injector: func(ir *MockItemRepository, or *MockOfferRepository) {
+				ir.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Price: 1000, Status: ItemStatusOnSale}, nil).Times(1)
+				or.EXPECT().ExpireStale(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				or.EXPECT().ListByItemID(gomock.Any(), 1).Return([]Offer{{ID: 5, BuyerID: 3, Status: OfferStatusAccepted}}, nil).Times(1)
+			},
+			wants: wants{code: http.StatusConflict},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockOR := NewMockOfferRepository(ctrl)
+			tt.injector(mockIR, mockOR)
+			h := &Handlers{itemRepo: mockIR, offerRepo: mockOR}
+
+			form := url.Values{"price": {tt.price}}
+			req := httptest.NewRequest("POST", "/items/1/offers", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set(userIDHeader, tt.userID)
+			req.SetPathValue("item_id", "1")
+
+			rr := httptest.NewRecorder()
+			h.AddOffer(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+		})
+	}
+}
+
+func TestAcceptOffer(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID string
+		offer  *Offer
+		accept bool
+		wants
+	}{
+		"ok: seller accepts a pending offer": {
+			userID: "1",
+			offer:  &Offer{ID: 5, ItemID: 1, BuyerID: 2, Price: 800, Status: OfferStatusPending},
+			accept: true,
+			wants:  wants{code: http.StatusOK},
+		},
+		"ok: buyer accepts a counter offer": {
+			userID: "2",
+			offer:  &Offer{ID: 5, ItemID: 1, BuyerID: 2, Price: 800, CounterPrice: 900, Status: OfferStatusCountered},
+			accept: true,
+			wants:  wants{code: http.StatusOK},
+		},
+		"ng: buyer accepts own pending offer": {
+			userID: "2",
+			offer:  &Offer{ID: 5, ItemID: 1, BuyerID: 2, Price: 800, Status: OfferStatusPending},
+			wants:  wants{code: http.StatusForbidden},
+		},
+		"ng: offer has expired": {
+			userID: "1",
+			offer:  &Offer{ID: 5, ItemID: 1, BuyerID: 2, Price: 800, Status: OfferStatusExpired},
+			wants:  wants{code: http.StatusConflict},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockOR := NewMockOfferRepository(ctrl)
+			mockOR.EXPECT().ExpireStale(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			mockOR.EXPECT().GetByID(gomock.Any(), 5).Return(tt.offer, nil).Times(1)
+			mockIR.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Price: 1000, Status: ItemStatusOnSale}, nil).Times(1)
+			if tt.accept {
+				mockOR.EXPECT().Accept(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			}
+			h := &Handlers{itemRepo: mockIR, offerRepo: mockOR}
+
+			req := httptest.NewRequest("POST", "/offers/5/accept", nil)
+			req.Header.Set(userIDHeader, tt.userID)
+			req.SetPathValue("offer_id", "5")
+
+			rr := httptest.NewRecorder()
+			h.AcceptOffer(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+		})
+	}
+}
+
+func TestPurchaseItem(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code  int
+		price int
+	}
+	cases := map[string]struct {
+		userID string
+		// accepted is the accepted offer the repository finds in the transaction of the order
+		accepted *Offer
+		wants
+	}{
+		"ok: purchased at the item price": {
+			userID: "2",
+			wants:  wants{code: http.StatusCreated, price: 1000},
+		},
+		"ok: purchased at the agreed price": {
+			userID:   "2",
+			accepted: &Offer{ID: 5, ItemID: 1, BuyerID: 2, Price: 800, CounterPrice: 900, Status: OfferStatusAccepted},
+			wants:    wants{code: http.StatusCreated, price: 900},
+		},
+		"ng: reserved for another buyer": {
+			userID:   "3",
+			accepted: &Offer{ID: 5, ItemID: 1, BuyerID: 2, Price: 800, Status: OfferStatusAccepted},
+			wants:    wants{code: http.StatusConflict},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockOrR := NewMockOrderRepository(ctrl)
+			mockCR := NewMockConversationRepository(ctrl)
+			mockIR.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Price: 1000, Status: ItemStatusOnSale}, nil).Times(1)
+			mockOrR.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order *Order) error {
+				if tt.accepted != nil {
+					if tt.accepted.BuyerID != order.BuyerID {
+						return errItemReserved
+					}
+					order.Price = tt.accepted.AgreedPrice()
+					order.OfferID = tt.accepted.ID
+				}
+				return nil
+			}).Times(1)
+			if tt.wants.code == http.StatusCreated {
+				mockCR.EXPECT().CreateForOrder(gomock.Any(), gomock.Any()).Return(&Conversation{}, nil).Times(1)
+			}
+			h := &Handlers{itemRepo: mockIR, orderRepo: mockOrR, conversationRepo: mockCR}
+
+			req := httptest.NewRequest("POST", "/items/1/purchase", nil)
+			req.Header.Set(userIDHeader, tt.userID)
+			req.SetPathValue("item_id", "1")
+
+			rr := httptest.NewRecorder()
+			h.PurchaseItem(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+			if tt.wants.code >= 400 {
+				return
+			}
+
+			var got Order
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response body: %v", err)
+			}
+			if got.Price != tt.wants.price {
+				t.Errorf("expected price %d, got %d", tt.wants.price, got.Price)
+			}
+		})
+	}
+}
+
+func TestOfferAcceptAndPurchase(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	ctx := context.Background()
+	offerRepo := NewOfferRepository(db)
+	orderRepo := NewOrderRepository(db, nil)
+	now := time.Now().UTC()
+	item := &Item{Name: "jacket", CategoryID: 1, Image: "a.jpg", Price: 1000, SellerID: 1, Status: ItemStatusOnSale, CreatedAt: now}
+	if err := (&itemRepository{db: db}).Insert(ctx, item); err != nil {
+		t.Fatalf("failed to insert item: %v", err)
+	}
+	offer := &Offer{ItemID: item.ID, BuyerID: 2, Price: 800, Status: OfferStatusRejected, ExpiresAt: now.Add(time.Hour), CreatedAt: now, UpdatedAt: now}
+	if err := offerRepo.Insert(ctx, offer); err != nil {
+		t.Fatalf("failed to insert offer: %v", err)
+	}
+
+	// a closed offer can be neither accepted nor reopened
+	offer.Status = OfferStatusAccepted
+	if err := offerRepo.Accept(ctx, offer); !errors.Is(err, errOfferNotOpen) {
+		t.Fatalf("expected errOfferNotOpen, got %v", err)
+	}
+	offer.Status = OfferStatusCountered
+	if err := offerRepo.Update(ctx, offer); !errors.Is(err, errOfferNotOpen) {
+		t.Fatalf("expected errOfferNotOpen, got %v", err)
+	}
+
+	offer = &Offer{ItemID: item.ID, BuyerID: 2, Price: 800, Status: OfferStatusPending, ExpiresAt: now.Add(time.Hour), CreatedAt: now, UpdatedAt: now}
+	if err := offerRepo.Insert(ctx, offer); err != nil {
+		t.Fatalf("failed to insert offer: %v", err)
+	}
+	offer.Status = OfferStatusAccepted
+	if err := offerRepo.Accept(ctx, offer); err != nil {
+		t.Fatalf("failed to accept offer: %v", err)
+	}
+	// a rejection racing the acceptance does not overwrite it
+	offer.Status = OfferStatusRejected
+	if err := offerRepo.Update(ctx, offer); !errors.Is(err, errOfferNotOpen) {
+		t.Fatalf("expected errOfferNotOpen, got %v", err)
+	}
+
+	// another buyer cannot purchase the reserved item, and the failed purchase leaves it on sale
+	if err := orderRepo.Create(ctx, &Order{ItemID: item.ID, BuyerID: 3, SellerID: 1, Price: 1000, Status: OrderStatusPurchased, CreatedAt: now}); !errors.Is(err, errItemReserved) {
+		t.Fatalf("expected errItemReserved, got %v", err)
+	}
+
+	order := &Order{ItemID: item.ID, BuyerID: 2, SellerID: 1, Price: 1000, Status: OrderStatusPurchased, CreatedAt: now}
+	if err := orderRepo.Create(ctx, order); err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+	if order.Price != 800 || order.OfferID != offer.ID {
+		t.Errorf("expected the order through offer %d at 800, got %+v", offer.ID, order)
+	}
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// parseOrderID parses the order ID from the path.
+func parseOrderID(r *http.Request) (int, error) {
+	orderID, err := strconv.Atoi(r.PathValue("order_id"))
+	if err != nil || orderID < 1 {
+		return 0, errors.New("invalid order ID")
+	}
+	return orderID, nil
+}
+
+// PurchaseItem is a handler to buy an item for POST /items/{item_id}/purchase .
+// If an offer on the item has been accepted, only its buyer can purchase the item, at the agreed price.
+func (s *Handlers) PurchaseItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if item.SellerID == userID {
+		writeError(w, r, newAPIError(http.StatusForbidden, "sellers cannot purchase their own items"))
+		return
+	}
+	if item.Status != ItemStatusOnSale {
+		writeError(w, r, errItemNotOnSale)
+		return
+	}
+
+	now := time.Now().UTC()
+	order := &Order{
+		ItemID:    item.ID,
+		BuyerID:   userID,
+		SellerID:  item.SellerID,
+		Price:     item.Price,
+		Status:    OrderStatusPurchased,
+		CreatedAt: now,
+	}
+
+	// the accepted offer is checked in the transaction of the order, so that it cannot change in between
+	if err := s.orderRepo.Create(ctx, order); err != nil {
+		writeError(w, r, fmt.Errorf("failed to create order: %w", err))
+		return
+	}
+
+	// the purchase has been made, so a failure here is only logged; GetOrderConversation retries it
+	if _, err := s.conversationRepo.CreateForOrder(ctx, order); err != nil {
+		slog.ErrorContext(ctx, "failed to create conversation: ", "error", err, "order_id", order.ID)
+	}
+
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// GetOrder is a handler to return an order to its buyer or seller for GET /orders/{order_id} .
+func (s *Handlers) GetOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	orderID, err := parseOrderID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil && !errors.Is(err, errOrderNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get order: %w", err))
+		return
+	}
+	if err != nil || order.BuyerID != userID && order.SellerID != userID {
+		writeError(w, r, errOrderNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, order)
+}
+
+// CompleteOrder is a handler for the buyer to confirm receipt of the item for POST /orders/{order_id}/complete .
+// Both parties can rate each other once the order is completed.
+func (s *Handlers) CompleteOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	orderID, err := parseOrderID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil && !errors.Is(err, errOrderNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get order: %w", err))
+		return
+	}
+	if err != nil || order.BuyerID != userID && order.SellerID != userID {
+		writeError(w, r, errOrderNotFound)
+		return
+	}
+	if order.BuyerID != userID {
+		writeError(w, r, newAPIError(http.StatusForbidden, "only the buyer can complete the order"))
+		return
+	}
+
+	err = s.orderRepo.UpdateStatus(ctx, orderID, OrderStatusPurchased, OrderStatusCompleted)
+	if err != nil {
+		if errors.Is(err, errOrderStatusConflict) {
+			writeError(w, r, fmt.Errorf("%w: order is already %s", err, order.Status))
+			return
+		}
+		writeError(w, r, fmt.Errorf("failed to complete order: %w", err))
+		return
+	}
+	order.Status = OrderStatusCompleted
+
+	writeJSON(w, http.StatusOK, order)
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errOrderNotFound       = errors.New("order not found")
+	errOrderStatusConflict = errors.New("order status has changed")
+)
+
+// OrderStatus is the progress of an order.
+type OrderStatus string
+
+const (
+	OrderStatusPurchased OrderStatus = "purchased"
+	// OrderStatusCompleted means the buyer has received the item.
+	OrderStatusCompleted OrderStatus = "completed"
+)
+
+type Order struct {
+	ID       int `db:"id" json:"id"`
+	ItemID   int `db:"item_id" json:"item_id"`
+	BuyerID  int `db:"buyer_id" json:"buyer_id"`
+	SellerID int `db:"seller_id" json:"seller_id"`
+	Price    int `db:"price" json:"price"`
+	// OfferID is the accepted offer the order was made through, or 0.
+	OfferID   int         `db:"offer_id" json:"offer_id,omitempty"`
+	Status    OrderStatus `db:"status" json:"status"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+}
+
+// OrderRepository is an interface to manage orders.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type OrderRepository interface {
+	// Create marks the item as sold out and records the order in one transaction.
+	// If an unexpired offer on the item has been accepted, the order is made through it at the agreed price,
+	// and errItemReserved is returned unless the buyer of the order is the buyer of the offer.
+	// It returns errItemNotOnSale if the item has already been sold.
+	Create(ctx context.Context, order *Order) error
+	GetByID(ctx context.Context, orderID int) (*Order, error)
+	// UpdateStatus changes the status of the order only if it is currently from,
+	// and returns errOrderStatusConflict otherwise.
+	UpdateStatus(ctx context.Context, orderID int, from, to OrderStatus) error
+}
+
+// orderRepository is an implementation of OrderRepository
+type orderRepository struct {
+	db *sql.DB
+	// index drops the items as they are sold, if it is not nil.
+	index *SimilarityIndex
+}
+
+// NewOrderRepository creates a new orderRepository keeping the index up to date.
+func NewOrderRepository(database *sql.DB, index *SimilarityIndex) OrderRepository {
+	return &orderRepository{db: database, index: index}
+}
+
+func (o *orderRepository) Create(ctx context.Context, order *Order) error {
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE items SET status = ? WHERE id = ? AND status = ?",
+		ItemStatusSoldOut, order.ItemID, ItemStatusOnSale)
+	if err != nil {
+		return fmt.Errorf("failed to update item status: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update item status: %w", err)
+	}
+	if n == 0 {
+		return errItemNotOnSale
+	}
+
+	// the offer is read after the item is locked by the update, so that it cannot be accepted in the meantime
+	var offer Offer
+	err = scanOffer(tx.QueryRowContext(ctx, "SELECT "+offerColumns+" FROM offers WHERE item_id = ? AND status = ? AND expires_at > ?",
+		order.ItemID, OfferStatusAccepted, order.CreatedAt), &offer)
+	switch {
+	case err == nil:
+		if offer.BuyerID != order.BuyerID {
+			return errItemReserved
+		}
+		order.Price = offer.AgreedPrice()
+		order.OfferID = offer.ID
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("failed to query accepted offer: %w", err)
+	}
+
+	res, err = tx.ExecContext(ctx, `
+		INSERT INTO orders (item_id, buyer_id, seller_id, price, offer_id, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		order.ItemID, order.BuyerID, order.SellerID, order.Price, order.OfferID, order.Status, order.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert order: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get order id: %w", err)
+	}
+	order.ID = int(id)
+
+	if order.OfferID != 0 {
+		_, err = tx.ExecContext(ctx, "UPDATE offers SET status = ?, updated_at = ? WHERE id = ?",
+			OfferStatusCompleted, order.CreatedAt, order.OfferID)
+		if err != nil {
+			return fmt.Errorf("failed to complete offer: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	o.index.Remove(order.ItemID)
+	return nil
+}
+
+func (o *orderRepository) GetByID(ctx context.Context, orderID int) (*Order, error) {
+	var order Order
+	err := o.db.QueryRowContext(ctx, `
+		SELECT id, item_id, buyer_id, seller_id, price, offer_id, status, created_at
+		FROM orders WHERE id = ?`, orderID).
+		Scan(&order.ID, &order.ItemID, &order.BuyerID, &order.SellerID, &order.Price, &order.OfferID, &order.Status, &order.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to query order: %w", err)
+	}
+	return &order, nil
+}
+
+func (o *orderRepository) UpdateStatus(ctx context.Context, orderID int, from, to OrderStatus) error {
+	res, err := o.db.ExecContext(ctx, "UPDATE orders SET status = ? WHERE id = ? AND status = ?", to, orderID, from)
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if n == 0 {
+		return errOrderStatusConflict
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// comparableCandidateLimit is the number of recent items matching the keywords examined for a suggestion.
+	comparableCandidateLimit = 500
+	// minNameSimilarity is the share of the keywords a comparable item's name must contain.
+	minNameSimilarity = 0.5
+	// minPriceSampleSize is the number of comparable items needed to suggest a price.
+	minPriceSampleSize = 3
+	// outlierFence is how many interquartile ranges a price may lie outside the quartiles before it is trimmed.
+	outlierFence = 1.5
+)
+
+// PriceSuggestion summarizes the prices of the items comparable to the one being listed.
+// The prices are omitted when there are less than minPriceSampleSize comparable items.
+type PriceSuggestion struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	// SampleSize is the number of comparable items the prices are computed over, after trimming outliers.
+	SampleSize      int `json:"sample_size"`
+	OutliersTrimmed int `json:"outliers_trimmed"`
+
+	Median        int `json:"median,omitempty"`
+	LowerQuartile int `json:"lower_quartile,omitempty"`
+	UpperQuartile int `json:"upper_quartile,omitempty"`
+}
+
+// nameSimilarity returns the share of the keywords contained in the name.
+func nameSimilarity(keywords []string, name string) float64 {
+	if len(keywords) == 0 {
+		return 0
+	}
+	name = strings.ToLower(norm.NFKC.String(name))
+	matched := 0
+	for _, k := range keywords {
+		if strings.Contains(name, k) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(keywords))
+}
+
+// quantile returns the q-quantile of the sorted values, interpolating linearly between the closest ranks.
+func quantile(sorted []int, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return float64(sorted[lower]) + (pos-float64(lower))*float64(sorted[upper]-sorted[lower])
+}
+
+// trimOutliers removes the values farther than outlierFence interquartile ranges from the quartiles.
+// The values must be sorted, and fewer than four values are returned as is.
+func trimOutliers(sorted []int) []int {
+	if len(sorted) < 4 {
+		return sorted
+	}
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	low, high := q1-outlierFence*(q3-q1), q3+outlierFence*(q3-q1)
+
+	trimmed := make([]int, 0, len(sorted))
+	for _, v := range sorted {
+		if float64(v) >= low && float64(v) <= high {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
+
+// GetPriceSuggestion is a handler to suggest a price for GET /price-suggestion?name=&category= .
+// The suggestion is computed over the listed and sold items of the category with similar names.
+func (s *Handlers) GetPriceSuggestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	category := strings.TrimSpace(r.URL.Query().Get("category"))
+	var v validator
+	v.required("name", name)
+	v.required("category", category)
+	if err := v.err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	keywords := nameTokens(name)
+
+	candidates, err := s.itemRepo.ListComparable(ctx, category, keywords, comparableCandidateLimit)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get comparable items: %w", err))
+		return
+	}
+
+	var prices []int
+	for _, item := range candidates {
+		if nameSimilarity(keywords, item.Name) >= minNameSimilarity {
+			prices = append(prices, item.Price)
+		}
+	}
+	slices.Sort(prices)
+	trimmed := trimOutliers(prices)
+
+	suggestion := PriceSuggestion{
+		Name:            name,
+		Category:        category,
+		SampleSize:      len(trimmed),
+		OutliersTrimmed: len(prices) - len(trimmed),
+	}
+	if len(trimmed) >= minPriceSampleSize {
+		suggestion.Median = int(math.Round(quantile(trimmed, 0.5)))
+		suggestion.LowerQuartile = int(math.Round(quantile(trimmed, 0.25)))
+		suggestion.UpperQuartile = int(math.Round(quantile(trimmed, 0.75)))
+	}
+
+	writeJSON(w, http.StatusOK, suggestion)
+}
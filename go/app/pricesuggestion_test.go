@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTrimOutliers(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		in   []int
+		want []int
+	}{
+		"ok: no outliers":      {in: []int{1000, 1200, 1300, 1500}, want: []int{1000, 1200, 1300, 1500}},
+		"ok: both ends":        {in: []int{10, 1000, 1100, 1200, 1300, 1400, 99999}, want: []int{1000, 1100, 1200, 1300, 1400}},
+		"ok: too few to trim":  {in: []int{10, 1000, 99999}, want: []int{10, 1000, 99999}},
+		"ok: identical values": {in: []int{500, 500, 500, 500, 501}, want: []int{500, 500, 500, 500}},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, trimOutliers(tt.in)); diff != "" {
+				t.Errorf("unexpected values (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetPriceSuggestion(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code       int
+		suggestion PriceSuggestion
+	}
+	cases := map[string]struct {
+		query string
+		// injector is used to inject the expected calls to the mock
+		injector func(m *MockItemRepository)
+		wants
+	}{
+		"ok: similar items": {
+			query: "?name=Nike+Air+Jacket&category=fashion",
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().ListComparable(gomock.Any(), "fashion", []string{"nike", "air", "jacket"}, comparableCandidateLimit).Return([]Item{
+					{Name: "nike air jacket", Price: 3000},
+					{Name: "NIKE jacket black", Price: 4000},
+					{Name: "Ｎｉｋｅ Air", Price: 5000},
+					{Name: "nike air jacket", Price: 3500},
+					{Name: "nike jacket", Price: 4500},
+					{Name: "nike air jacket fake", Price: 100},
+					// less than half of the keywords
+					{Name: "denim jacket", Price: 8000},
+				}, nil)
+			},
+			wants: wants{code: http.StatusOK, suggestion: PriceSuggestion{
+				Name: "Nike Air Jacket", Category: "fashion",
+				SampleSize: 5, OutliersTrimmed: 1,
+				Median: 4000, LowerQuartile: 3500, UpperQuartile: 4500,
+			}},
+		},
+		"ok: not enough items": {
+			query: "?name=jacket&category=fashion",
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().ListComparable(gomock.Any(), "fashion", []string{"jacket"}, comparableCandidateLimit).Return([]Item{{Name: "jacket", Price: 3000}}, nil)
+			},
+			wants: wants{code: http.StatusOK, suggestion: PriceSuggestion{Name: "jacket", Category: "fashion", SampleSize: 1}},
+		},
+		"ng: no category": {
+			query:    "?name=jacket",
+			injector: func(m *MockItemRepository) {},
+			wants:    wants{code: http.StatusUnprocessableEntity},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			tt.injector(mockIR)
+			h := &Handlers{itemRepo: mockIR}
+
+			req := httptest.NewRequest("GET", "/price-suggestion"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			h.GetPriceSuggestion(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Fatalf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+			if rr.Code != http.StatusOK {
+				return
+			}
+			var got PriceSuggestion
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if diff := cmp.Diff(tt.wants.suggestion, got); diff != "" {
+				t.Errorf("unexpected suggestion (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestItemRepositoryListComparable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	repo := &itemRepository{db: db}
+	ctx := context.Background()
+	if _, err := db.Exec("INSERT INTO categories (id, name) VALUES (1, 'fashion')"); err != nil {
+		t.Fatalf("failed to insert category: %v", err)
+	}
+	for _, item := range []*Item{
+		{Name: "priced jacket", CategoryID: 1, Image: "a.jpg", Price: 3000},
+		// the items listed before prices were added have no price
+		{Name: "unpriced jacket", CategoryID: 1, Image: "a.jpg"},
+		{Name: "draft jacket", CategoryID: 1, Image: "a.jpg", Price: 3000, Status: ItemStatusDraft},
+	} {
+		if err := repo.Insert(ctx, item); err != nil {
+			t.Fatalf("failed to insert item: %v", err)
+		}
+	}
+
+	items, err := repo.ListComparable(ctx, "fashion", []string{"jacket"}, 10)
+	if err != nil {
+		t.Fatalf("failed to list comparable items: %v", err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	if diff := cmp.Diff([]string{"priced jacket"}, names); diff != "" {
+		t.Errorf("unexpected comparable items (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// rateLimitPruneInterval is how often the idle clients are forgotten by the rate limiter.
+const rateLimitPruneInterval = 5 * time.Minute
+
+// RateLimit is the limit of a token bucket: it allows Burst requests at once,
+// and Rate requests per second in the long run. The zero RateLimit allows every request.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// perMinute creates the limit of n requests per minute with bursts of burst requests.
+func perMinute(n, burst int) RateLimit {
+	return RateLimit{Rate: float64(n) / 60, Burst: burst}
+}
+
+// enabled reports whether the limit limits anything.
+func (l RateLimit) enabled() bool {
+	return l.Burst > 0 && l.Rate > 0
+}
+
+// defaultRateLimit applies to the routes without their own limit in routeRateLimits,
+// including the requests matching no route.
+var defaultRateLimit = perMinute(300, 60)
+
+// routeRateLimits are the limits by the pattern of the ServeMux. Listing items, searching and
+// the other writes cost more or can be used for spam, so they have lower limits. Search clicks
+// feed the trending scores without authentication, so they are limited to what a person clicks.
+// The probes and the scrapes are not limited.
+var routeRateLimits = map[string]RateLimit{
+	"GET /healthz": {},
+	"GET /readyz":  {},
+	"GET /metrics": {},
+
+	"POST /items":            perMinute(10, 5),
+	"PATCH /items/{item_id}": perMinute(30, 10),
+	"GET /search":            perMinute(60, 20),
+	"GET /price-suggestion":  perMinute(60, 20),
+
+	"POST /items/{item_id}/comments":                 perMinute(20, 5),
+	"POST /items/{item_id}/offers":                   perMinute(20, 5),
+	"POST /items/{item_id}/reports":                  perMinute(10, 3),
+	"POST /conversations/{conversation_id}/messages": perMinute(30, 10),
+	"POST /saved-searches":                           perMinute(10, 5),
+	"POST /items/{item_id}/search-clicks":            perMinute(20, 5),
+}
+
+// rateLimitKey identifies the client of a request for rate limiting by its address.
+// The user ID is not authenticated yet, so keying on it would let a client get new buckets
+// by sending another user ID with each request.
+func rateLimitKey(r *http.Request) string {
+	return visitorKey(r, 0)
+}
+
+// rateLimitMiddleware limits the requests of each client to each route of the ServeMux,
+// with the limits in limits or else defaultRateLimit. Every limited response tells the state of
+// the bucket in the RateLimit-* headers, and the rejected requests get 429 with Retry-After.
+// A failing store lets the requests through, so that it doesn't take the API down.
+func rateLimitMiddleware(mux *http.ServeMux, store RateLimitStore, limits map[string]RateLimit) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := mux.Handler(r)
+		limit, ok := limits[pattern]
+		if !ok {
+			limit = defaultRateLimit
+		}
+		if !limit.enabled() {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		route := pattern
+		if route == "" {
+			route = unmatchedRoute
+		}
+		res, err := store.Take(r.Context(), route+" "+rateLimitKey(r), limit, time.Now())
+		if err != nil {
+			slog.WarnContext(r.Context(), "failed to take rate limit token", "error", err)
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, ceilSeconds(secondsDuration(float64(limit.Burst)/limit.Rate))))
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			retryAfter := max(ceilSeconds(res.RetryAfter), 1)
+			h.Set("Retry-After", strconv.Itoa(retryAfter))
+			// the mux doesn't see the request, so the pattern is set for the logs and the metrics
+			r.Pattern = pattern
+			writeError(w, r, newAPIError(http.StatusTooManyRequests, fmt.Sprintf("too many requests, retry after %ds", retryAfter)))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ceilSeconds rounds a duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
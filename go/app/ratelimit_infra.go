@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitResult is the state of a token bucket after a request has taken a token from it.
+type RateLimitResult struct {
+	// Allowed is whether the request got a token.
+	Allowed bool
+	// Remaining is the number of tokens left.
+	Remaining int
+	// RetryAfter is how long until the next token, when the request is not allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// RateLimitStore keeps the token buckets of the rate limiter by key.
+// MemoryRateLimitStore serves a single server; a store shared by several servers,
+// such as one on Redis, lets them enforce the same limits.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of the key at now, creating a full bucket for a new key.
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+// tokenBucket is a token bucket refilled at the rate of its limit, up to the burst.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket up to now and takes a token from it if there is one.
+func (b *tokenBucket) take(limit RateLimit, now time.Time) RateLimitResult {
+	if b.updated.IsZero() {
+		b.tokens = float64(limit.Burst)
+	} else if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(float64(limit.Burst), b.tokens+elapsed.Seconds()*limit.Rate)
+	}
+	if now.After(b.updated) {
+		b.updated = now
+	}
+
+	var res RateLimitResult
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsDuration((1 - b.tokens) / limit.Rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = secondsDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return res
+}
+
+// full reports whether the bucket has been refilled by now, so that it is the same as a new one.
+func (b *tokenBucket) full(limit RateLimit, now time.Time) bool {
+	return b.tokens+now.Sub(b.updated).Seconds()*limit.Rate >= float64(limit.Burst)
+}
+
+// secondsDuration converts seconds to a duration.
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// MemoryRateLimitStore keeps the token buckets in memory.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+type memoryBucket struct {
+	tokenBucket
+	limit RateLimit
+}
+
+// NewMemoryRateLimitStore creates an empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket)}
+}
+
+// Take takes a token from the bucket of the key at now. It never fails.
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	return b.take(limit, now), nil
+}
+
+// Prune forgets the buckets that are full again, so that the clients seen once don't stay in memory.
+// It is run periodically by the scheduler started in Server.Run.
+func (s *MemoryRateLimitStore) Prune(ctx context.Context) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, b := range s.buckets {
+		if b.full(b.limit, now) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	t.Parallel()
+
+	limit := RateLimit{Rate: 1, Burst: 2}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []struct {
+		after time.Duration
+		want  RateLimitResult
+	}{
+		{after: 0, want: RateLimitResult{Allowed: true, Remaining: 1, Reset: time.Second}},
+		{after: 0, want: RateLimitResult{Allowed: true, Remaining: 0, Reset: 2 * time.Second}},
+		{after: 0, want: RateLimitResult{Allowed: false, Remaining: 0, RetryAfter: time.Second, Reset: 2 * time.Second}},
+		{after: 500 * time.Millisecond, want: RateLimitResult{Allowed: false, Remaining: 0, RetryAfter: 500 * time.Millisecond, Reset: 1500 * time.Millisecond}},
+		{after: time.Second, want: RateLimitResult{Allowed: true, Remaining: 0, Reset: 2 * time.Second}},
+	}
+
+	store := NewMemoryRateLimitStore()
+	for i, step := range steps {
+		got, err := store.Take(context.Background(), "client", limit, start.Add(step.after))
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if diff := cmp.Diff(step.want, got); diff != "" {
+			t.Errorf("step %d: unexpected result (-want +got):\n%s", i, diff)
+		}
+	}
+
+	// other keys have their own buckets
+	if got, _ := store.Take(context.Background(), "other", limit, start); !got.Allowed {
+		t.Errorf("expected another key to be allowed, got %+v", got)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	mux.HandleFunc("POST /items", ok)
+	mux.HandleFunc("GET /healthz", ok)
+	limits := map[string]RateLimit{
+		"POST /items":  perMinute(1, 2),
+		"GET /healthz": {},
+	}
+	handler := rateLimitMiddleware(mux, NewMemoryRateLimitStore(), limits)
+
+	send := func(method, path, addr, userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = addr
+		if userID != "" {
+			req.Header.Set(userIDHeader, userID)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		rr := send("POST", "/items", "192.0.2.1:1234", "1")
+		if rr.Code != want {
+			t.Fatalf("request %d: expected status code %d, got %d", i, want, rr.Code)
+		}
+		if got := rr.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: expected RateLimit-Limit 2, got %q", i, got)
+		}
+		if want == http.StatusTooManyRequests {
+			if got := rr.Header().Get("Retry-After"); got != "60" {
+				t.Errorf("expected Retry-After 60, got %q", got)
+			}
+			if got := rr.Header().Get("RateLimit-Remaining"); got != "0" {
+				t.Errorf("expected RateLimit-Remaining 0, got %q", got)
+			}
+		}
+	}
+
+	// rotating the user ID doesn't give the same address a new bucket
+	for _, userID := range []string{"2", "3", ""} {
+		if rr := send("POST", "/items", "192.0.2.1:5678", userID); rr.Code != http.StatusTooManyRequests {
+			t.Errorf("expected user %q of the same address to be limited, got %d", userID, rr.Code)
+		}
+	}
+
+	// the other clients and the routes without a limit are not affected
+	if rr := send("POST", "/items", "192.0.2.2:1234", "1"); rr.Code != http.StatusOK {
+		t.Errorf("expected another address to be allowed, got %d", rr.Code)
+	}
+	for range 3 {
+		rr := send("GET", "/healthz", "192.0.2.1:1234", "1")
+		if rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("expected the probe not to be limited, got %d %v", rr.Code, rr.Header())
+		}
+	}
+}
+
+func TestRateLimitMiddlewareStoreFailure(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	store := NewMockRateLimitStore(ctrl)
+	store.EXPECT().Take(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(RateLimitResult{}, errors.New("connection refused"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := rateLimitMiddleware(mux, store, routeRateLimits)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/search", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected the request to be let through, got %d", rr.Code)
+	}
+}
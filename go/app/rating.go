@@ -0,0 +1,122 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const maxRatingCommentLength = 1000
+
+type AddRatingRequest struct {
+	OrderID int
+	Value   RatingValue `json:"rating"`
+	Comment string      `json:"comment"`
+}
+
+// parseAddRatingRequest parses and validates the request to rate the other party of an order.
+func parseAddRatingRequest(r *http.Request) (*AddRatingRequest, error) {
+	orderID, err := parseOrderID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &AddRatingRequest{
+		OrderID: orderID,
+		Value:   RatingValue(r.FormValue("rating")),
+		Comment: strings.TrimSpace(r.FormValue("comment")),
+	}
+
+	// validate the request
+	var v validator
+	v.check(req.Value.Valid(), "rating", "rating must be one of good, normal or bad")
+	v.maxLength("comment", req.Comment, maxRatingCommentLength)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// AddRating is a handler to rate the other party of a completed order for POST /orders/{order_id}/ratings .
+// The buyer and the seller can each rate the order exactly once.
+func (s *Handlers) AddRating(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	req, err := parseAddRatingRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil && !errors.Is(err, errOrderNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get order: %w", err))
+		return
+	}
+	if err != nil || order.BuyerID != userID && order.SellerID != userID {
+		writeError(w, r, errOrderNotFound)
+		return
+	}
+	if order.Status != OrderStatusCompleted {
+		writeError(w, r, newAPIError(http.StatusConflict, "order is not completed yet"))
+		return
+	}
+
+	rating := &Rating{
+		OrderID: order.ID,
+		RaterID: userID,
+		RateeID: order.SellerID,
+		Value:   req.Value,
+		Comment: req.Comment,
+	}
+	if userID == order.SellerID {
+		rating.RateeID = order.BuyerID
+	}
+
+	if err := s.ratingRepo.Insert(ctx, rating); err != nil {
+		writeError(w, r, fmt.Errorf("failed to store rating: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rating)
+}
+
+type SellerProfileResponse struct {
+	ID      int           `json:"id"`
+	Ratings RatingSummary `json:"ratings"`
+}
+
+// GetSellerProfile is a handler to return the public profile of a seller for GET /sellers/{seller_id} .
+// Users who have never listed an item are not sellers.
+func (s *Handlers) GetSellerProfile(w http.ResponseWriter, r *http.Request) {
+	sellerID, err := parseSellerID(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	exists, err := s.itemRepo.HasListedItems(r.Context(), sellerID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get seller: %w", err))
+		return
+	}
+	if !exists {
+		writeError(w, r, errSellerNotFound)
+		return
+	}
+
+	summary, err := s.ratingRepo.GetSellerSummary(r.Context(), sellerID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get ratings: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SellerProfileResponse{ID: sellerID, Ratings: *summary})
+}
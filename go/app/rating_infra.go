@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var errAlreadyRated = errors.New("order has already been rated")
+
+// RatingValue is the evaluation left on a user after an order.
+type RatingValue string
+
+const (
+	RatingGood   RatingValue = "good"
+	RatingNormal RatingValue = "normal"
+	RatingBad    RatingValue = "bad"
+)
+
+// Valid reports whether the value is one of the defined ratings.
+func (v RatingValue) Valid() bool {
+	return v == RatingGood || v == RatingNormal || v == RatingBad
+}
+
+type Rating struct {
+	ID      int         `db:"id" json:"id"`
+	OrderID int         `db:"order_id" json:"order_id"`
+	RaterID int         `db:"rater_id" json:"rater_id"`
+	RateeID int         `db:"ratee_id" json:"ratee_id"`
+	Value   RatingValue `db:"rating" json:"rating"`
+	Comment string      `db:"comment" json:"comment"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// RatingSummary is the number of ratings a seller has received from the buyers per value.
+type RatingSummary struct {
+	Good   int `json:"good"`
+	Normal int `json:"normal"`
+	Bad    int `json:"bad"`
+}
+
+// RatingRepository is an interface to manage ratings between buyers and sellers.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type RatingRepository interface {
+	// Insert stores a rating and returns errAlreadyRated if the rater has already rated the order.
+	Insert(ctx context.Context, rating *Rating) error
+	// GetSellerSummary aggregates the ratings the user has received on the orders where they were the seller.
+	GetSellerSummary(ctx context.Context, userID int) (*RatingSummary, error)
+}
+
+// ratingRepository is an implementation of RatingRepository
+type ratingRepository struct {
+	db *sql.DB
+}
+
+// NewRatingRepository creates a new ratingRepository.
+func NewRatingRepository(database *sql.DB) RatingRepository {
+	return &ratingRepository{db: database}
+}
+
+func (rr *ratingRepository) Insert(ctx context.Context, rating *Rating) error {
+	rating.CreatedAt = time.Now().UTC()
+	res, err := rr.db.ExecContext(ctx, `
+		INSERT INTO ratings (order_id, rater_id, ratee_id, rating, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		rating.OrderID, rating.RaterID, rating.RateeID, rating.Value, rating.Comment, rating.CreatedAt)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return errAlreadyRated
+		}
+		return fmt.Errorf("failed to insert rating: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get rating id: %w", err)
+	}
+	rating.ID = int(id)
+	return nil
+}
+
+func (rr *ratingRepository) GetSellerSummary(ctx context.Context, userID int) (*RatingSummary, error) {
+	var summary RatingSummary
+	err := rr.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(ratings.rating = ?), 0),
+			COALESCE(SUM(ratings.rating = ?), 0),
+			COALESCE(SUM(ratings.rating = ?), 0)
+		FROM ratings
+		JOIN orders ON orders.id = ratings.order_id
+		WHERE ratings.ratee_id = ? AND orders.seller_id = ratings.ratee_id`, RatingGood, RatingNormal, RatingBad, userID).
+		Scan(&summary.Good, &summary.Normal, &summary.Bad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate ratings: %w", err)
+	}
+	return &summary, nil
+}
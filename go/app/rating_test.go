@@ -0,0 +1,235 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddRating(t *testing.T) {
+	t.Parallel()
+
+	completed := &Order{ID: 3, ItemID: 1, BuyerID: 2, SellerID: 1, Status: OrderStatusCompleted}
+
+	type wants struct {
+		code    int
+		rateeID int
+	}
+	cases := map[string]struct {
+		userID   string
+		rating   string
+		order    *Order
+		inserted error
+		wants
+	}{
+		"ok: buyer rates the seller": {
+			userID: "2",
+			rating: "good",
+			order:  completed,
+			wants:  wants{code: http.StatusCreated, rateeID: 1},
+		},
+		"ok: seller rates the buyer": {
+			userID: "1",
+			rating: "normal",
+			order:  completed,
+			wants:  wants{code: http.StatusCreated, rateeID: 2},
+		},
+		"ng: already rated": {
+			userID:   "2",
+			rating:   "bad",
+			order:    completed,
+			inserted: errAlreadyRated,
+			wants:    wants{code: http.StatusConflict, rateeID: 1},
+		},
+		"ng: order is not completed": {
+			userID: "2",
+			rating: "good",
+			order:  &Order{ID: 3, ItemID: 1, BuyerID: 2, SellerID: 1, Status: OrderStatusPurchased},
+			wants:  wants{code: http.StatusConflict},
+		},
+		"ng: outsider cannot rate": {
+			userID: "3",
+			rating: "good",
+			order:  completed,
+			wants:  wants{code: http.StatusNotFound},
+		},
+		"ng: unknown rating": {
+			userID: "2",
+			rating: "excellent",
+			wants:  wants{code: http.StatusUnprocessableEntity},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockOR := NewMockOrderRepository(ctrl)
+			mockRR := NewMockRatingRepository(ctrl)
+			if tt.order != nil {
+				mockOR.EXPECT().GetByID(gomock.Any(), 3).Return(tt.order, nil).Times(1)
+			}
+			if tt.wants.rateeID != 0 {
+				mockRR.EXPECT().Insert(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r *Rating) error {
+					if r.RateeID != tt.wants.rateeID {
+						t.Errorf("expected ratee %d, got %d", tt.wants.rateeID, r.RateeID)
+					}
+					return tt.inserted
+				}).Times(1)
+			}
+			h := &Handlers{orderRepo: mockOR, ratingRepo: mockRR}
+
+			form := url.Values{"rating": {tt.rating}, "comment": {"Thank you!"}}
+			req := httptest.NewRequest("POST", "/orders/3/ratings", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set(userIDHeader, tt.userID)
+			req.SetPathValue("order_id", "3")
+
+			rr := httptest.NewRecorder()
+			h.AddRating(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+		})
+	}
+}
+
+func TestGetSellerProfile(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		// injector is used to inject the expected calls to the mocks
+		injector func(ir *MockItemRepository, rr *MockRatingRepository)
+		wants
+	}{
+		"ok: seller with ratings": {
+			injector: func(ir *MockItemRepository, rr *MockRatingRepository) {
+				ir.EXPECT().HasListedItems(gomock.Any(), 1).Return(true, nil)
+				rr.EXPECT().GetSellerSummary(gomock.Any(), 1).Return(&RatingSummary{Good: 2}, nil)
+			},
+			wants: wants{code: http.StatusOK},
+		},
+		"ng: user without listings": {
+			injector: func(ir *MockItemRepository, rr *MockRatingRepository) {
+				ir.EXPECT().HasListedItems(gomock.Any(), 1).Return(false, nil)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockRR := NewMockRatingRepository(ctrl)
+			tt.injector(mockIR, mockRR)
+			h := &Handlers{itemRepo: mockIR, ratingRepo: mockRR}
+
+			req := httptest.NewRequest("GET", "/sellers/1", nil)
+			req.SetPathValue("seller_id", "1")
+			rr := httptest.NewRecorder()
+			h.GetSellerProfile(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestRatingRepositoryGetSellerSummary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	ctx := context.Background()
+	// user 1 sells order 1 and buys order 2
+	for _, o := range []struct{ id, buyerID, sellerID int }{{1, 2, 1}, {2, 1, 3}} {
+		_, err := db.Exec("INSERT INTO orders (id, item_id, buyer_id, seller_id, price, status, created_at) VALUES (?, ?, ?, ?, 1000, ?, ?)",
+			o.id, o.id, o.buyerID, o.sellerID, OrderStatusCompleted, time.Now().UTC())
+		if err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+	}
+	repo := NewRatingRepository(db)
+	for _, rating := range []*Rating{
+		{OrderID: 1, RaterID: 2, RateeID: 1, Value: RatingGood},
+		{OrderID: 1, RaterID: 1, RateeID: 2, Value: RatingGood},
+		{OrderID: 2, RaterID: 3, RateeID: 1, Value: RatingBad},
+	} {
+		if err := repo.Insert(ctx, rating); err != nil {
+			t.Fatalf("failed to insert rating: %v", err)
+		}
+	}
+
+	// the rating received as a buyer doesn't count
+	got, err := repo.GetSellerSummary(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed to get summary: %v", err)
+	}
+	if diff := cmp.Diff(&RatingSummary{Good: 1}, got); diff != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", diff)
+	}
+}
+
+func TestItemRepositoryHasListedItems(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	ctx := context.Background()
+	repo := &itemRepository{db: db}
+	for _, item := range []*Item{
+		{Name: "jacket", CategoryID: 1, Image: "a.jpg", SellerID: 1, Status: ItemStatusSoldOut},
+		// a draft doesn't make its owner a seller
+		{Name: "jacket", CategoryID: 1, Image: "a.jpg", SellerID: 2, Status: ItemStatusDraft},
+	} {
+		if err := repo.Insert(ctx, item); err != nil {
+			t.Fatalf("failed to insert item: %v", err)
+		}
+	}
+
+	for sellerID, want := range map[int]bool{1: true, 2: false, 3: false} {
+		got, err := repo.HasListedItems(ctx, sellerID)
+		if err != nil {
+			t.Fatalf("failed to check items of seller %d: %v", sellerID, err)
+		}
+		if got != want {
+			t.Errorf("expected seller %d to have listed items %v, got %v", sellerID, want, got)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	// reportHideThreshold is the number of open reports from different users hiding an item until a moderator resolves them.
+	reportHideThreshold = 3
+	// maxReportCommentLength is the maximum length of the free text of a report in characters.
+	maxReportCommentLength = 1000
+)
+
+// ReportItem is a handler to report an item for POST /items/{item_id}/reports .
+// An item reported by reportHideThreshold users is hidden until a moderator resolves the reports.
+func (s *Handlers) ReportItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	report := &Report{
+		ItemID:     itemID,
+		ReporterID: userID,
+		Reason:     ReportReason(r.FormValue("reason")),
+		Comment:    strings.TrimSpace(r.FormValue("comment")),
+	}
+	var v validator
+	v.check(report.Reason.valid(), "reason", "reason must be prohibited, counterfeit, inappropriate, spam or other")
+	v.maxLength("comment", report.Comment, maxReportCommentLength)
+	if err := v.err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+	if item.SellerID == userID {
+		writeError(w, r, newAPIError(http.StatusBadRequest, "cannot report your own item"))
+		return
+	}
+
+	open, err := s.reportRepo.Insert(ctx, report)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to store report: %w", err))
+		return
+	}
+
+	if open >= reportHideThreshold && item.Status == ItemStatusOnSale {
+		decision := &ModerationDecision{ItemID: itemID, Action: ModerationActionAutoHide, Note: fmt.Sprintf("%d open reports", open)}
+		// the report is stored anyway, and the next report retries hiding the item
+		if hidden, err := s.reportRepo.Hide(ctx, itemID, decision); err != nil {
+			slog.ErrorContext(ctx, "failed to hide reported item: ", "error", err)
+		} else if hidden {
+			slog.InfoContext(ctx, "reported item hidden", "item_id", itemID, "reports", open)
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, report)
+}
+
+// GetReports is a handler to return the items with open reports for GET /admin/reports .
+func (s *Handlers) GetReports(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.parseAdminID(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	summaries, err := s.reportRepo.ListOpenSummaries(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get reports: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Items  []ItemReportSummary `json:"items"`
+		Limit  int                 `json:"limit"`
+		Offset int                 `json:"offset"`
+	}{Items: summaries, Limit: limit, Offset: offset})
+}
+
+// GetItemReports is a handler to return the open reports of an item for GET /admin/reports/{item_id} .
+func (s *Handlers) GetItemReports(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.parseAdminID(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	reports, err := s.reportRepo.ListOpenByItemID(r.Context(), itemID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get reports: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Reports []Report `json:"reports"`
+	}{Reports: reports})
+}
+
+// ResolveReports is a handler to resolve the open reports of an item for POST /admin/reports/{item_id}/resolve .
+// The action "dismiss" lists the item again, and "remove" takes it down and tells the seller.
+func (s *Handlers) ResolveReports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	adminID, err := s.parseAdminID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	decision := &ModerationDecision{ItemID: itemID, ModeratorID: adminID, Note: strings.TrimSpace(r.FormValue("note"))}
+	var (
+		status     ReportStatus
+		itemStatus ItemStatus
+	)
+	switch action := r.FormValue("action"); action {
+	case "dismiss":
+		decision.Action, status, itemStatus = ModerationActionDismissReports, ReportStatusDismissed, ItemStatusOnSale
+	case "remove":
+		decision.Action, status, itemStatus = ModerationActionRemove, ReportStatusUpheld, ItemStatusRejected
+	default:
+		var v validator
+		v.oneOf("action", action, "dismiss", "remove")
+		writeError(w, r, v.err())
+		return
+	}
+
+	if err := s.reportRepo.Resolve(ctx, itemID, status, itemStatus, decision); err != nil {
+		writeError(w, r, fmt.Errorf("failed to resolve reports: %w", err))
+		return
+	}
+
+	if decision.Action == ModerationActionRemove {
+		// notifications are best effort and must not fail the decision
+		if err := s.notifyItemRemoved(ctx, decision); err != nil {
+			slog.ErrorContext(ctx, "failed to notify removed item: ", "error", err)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, decision)
+}
+
+// notifyItemRemoved tells the seller their item has been taken down after being reported.
+func (s *Handlers) notifyItemRemoved(ctx context.Context, decision *ModerationDecision) error {
+	item, err := s.itemRepo.GetByID(ctx, decision.ItemID)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf("Your item has been removed after reports: %s", item.Name)
+	if decision.Note != "" {
+		message += " (" + decision.Note + ")"
+	}
+	return s.notifier.Notify(ctx, &Notification{
+		UserID:  item.SellerID,
+		Type:    NotificationModerationResult,
+		ItemID:  item.ID,
+		Message: message,
+	})
+}
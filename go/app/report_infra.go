@@ -0,0 +1,299 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	errAlreadyReported = errors.New("item is already reported by the user")
+	errNoOpenReports   = errors.New("item has no open reports")
+)
+
+// ReportReason is the reason code of a report.
+type ReportReason string
+
+const (
+	ReportReasonProhibited    ReportReason = "prohibited"
+	ReportReasonCounterfeit   ReportReason = "counterfeit"
+	ReportReasonInappropriate ReportReason = "inappropriate"
+	ReportReasonSpam          ReportReason = "spam"
+	ReportReasonOther         ReportReason = "other"
+)
+
+// valid reports whether the reason is one of the known reason codes.
+func (r ReportReason) valid() bool {
+	switch r {
+	case ReportReasonProhibited, ReportReasonCounterfeit, ReportReasonInappropriate, ReportReasonSpam, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+// ReportStatus is the state of a report.
+type ReportStatus string
+
+const (
+	ReportStatusOpen ReportStatus = "open"
+	// ReportStatusDismissed is a report a moderator found no problem with.
+	ReportStatusDismissed ReportStatus = "dismissed"
+	// ReportStatusUpheld is a report that got the item removed.
+	ReportStatusUpheld ReportStatus = "upheld"
+)
+
+// Report is a report of an item by a user.
+type Report struct {
+	ID         int          `db:"id" json:"id"`
+	ItemID     int          `db:"item_id" json:"item_id"`
+	ReporterID int          `db:"reporter_id" json:"reporter_id"`
+	Reason     ReportReason `db:"reason" json:"reason"`
+	Comment    string       `db:"comment" json:"comment,omitempty"`
+	Status     ReportStatus `db:"status" json:"status"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ItemReportSummary aggregates the open reports of an item.
+type ItemReportSummary struct {
+	ItemID      int                  `json:"item_id"`
+	ReportCount int                  `json:"report_count"`
+	Reasons     map[ReportReason]int `json:"reasons"`
+
+	FirstReportedAt time.Time `json:"first_reported_at"`
+	LastReportedAt  time.Time `json:"last_reported_at"`
+}
+
+// ReportRepository is an interface to manage the reports of items.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type ReportRepository interface {
+	// Insert inserts the report and returns the number of open reports of the item.
+	// It returns errAlreadyReported if the user has reported the item before.
+	Insert(ctx context.Context, report *Report) (int, error)
+	// ListOpenSummaries returns the items with open reports, most reported first.
+	ListOpenSummaries(ctx context.Context, limit, offset int) ([]ItemReportSummary, error)
+	// ListOpenByItemID returns the open reports of the item, oldest first.
+	ListOpenByItemID(ctx context.Context, itemID int) ([]Report, error)
+	// Hide hides the item while its reports are reviewed, recording the decision.
+	// It returns false if the item was not on sale.
+	Hide(ctx context.Context, itemID int, decision *ModerationDecision) (bool, error)
+	// Resolve closes the open reports of the item with the status, sets the item to itemStatus
+	// unless it has been sold, and records the decision. It returns errNoOpenReports if there are none.
+	Resolve(ctx context.Context, itemID int, status ReportStatus, itemStatus ItemStatus, decision *ModerationDecision) error
+}
+
+// reportRepository is an implementation of ReportRepository
+type reportRepository struct {
+	db *sql.DB
+	// index is refreshed as the items are hidden and restored, if it is not nil.
+	index *SimilarityIndex
+}
+
+// NewReportRepository creates a new reportRepository keeping the index up to date.
+func NewReportRepository(database *sql.DB, index *SimilarityIndex) ReportRepository {
+	return &reportRepository{db: database, index: index}
+}
+
+func (rr *reportRepository) Insert(ctx context.Context, report *Report) (int, error) {
+	tx, err := rr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	report.Status = ReportStatusOpen
+	report.CreatedAt = time.Now().UTC()
+	res, err := tx.ExecContext(ctx, "INSERT INTO reports (item_id, reporter_id, reason, comment, status, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		report.ItemID, report.ReporterID, report.Reason, report.Comment, report.Status, report.CreatedAt)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return 0, errAlreadyReported
+		}
+		return 0, fmt.Errorf("failed to insert report: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get report id: %w", err)
+	}
+
+	var open int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM reports WHERE item_id = ? AND status = ?", report.ItemID, ReportStatusOpen).Scan(&open)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	report.ID = int(id)
+	return open, nil
+}
+
+func (rr *reportRepository) ListOpenSummaries(ctx context.Context, limit, offset int) ([]ItemReportSummary, error) {
+	rows, err := rr.db.QueryContext(ctx, `
+		SELECT item_id, COUNT(*), MIN(created_at), MAX(created_at) FROM reports
+		WHERE status = ?
+		GROUP BY item_id
+		ORDER BY COUNT(*) DESC, MIN(created_at)
+		LIMIT ? OFFSET ?`, ReportStatusOpen, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report summaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []ItemReportSummary{}
+	for rows.Next() {
+		// MIN and MAX lose the column type, so the times are parsed from the stored text
+		var first, last string
+		s := ItemReportSummary{Reasons: map[ReportReason]int{}}
+		if err := rows.Scan(&s.ItemID, &s.ReportCount, &first, &last); err != nil {
+			return nil, fmt.Errorf("failed to scan report summary: %w", err)
+		}
+		if s.FirstReportedAt, err = parseSQLiteTime(first); err != nil {
+			return nil, err
+		}
+		if s.LastReportedAt, err = parseSQLiteTime(last); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate report summaries: %w", err)
+	}
+	rows.Close()
+
+	for k := range summaries {
+		reasons, err := rr.db.QueryContext(ctx, "SELECT reason, COUNT(*) FROM reports WHERE item_id = ? AND status = ? GROUP BY reason",
+			summaries[k].ItemID, ReportStatusOpen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get report reasons: %w", err)
+		}
+		for reasons.Next() {
+			var (
+				reason ReportReason
+				count  int
+			)
+			if err := reasons.Scan(&reason, &count); err != nil {
+				reasons.Close()
+				return nil, fmt.Errorf("failed to scan report reason: %w", err)
+			}
+			summaries[k].Reasons[reason] = count
+		}
+		reasons.Close()
+		if err := reasons.Err(); err != nil {
+			return nil, fmt.Errorf("failed to iterate report reasons: %w", err)
+		}
+	}
+
+	return summaries, nil
+}
+
+// parseSQLiteTime parses a time as go-sqlite3 stores time.Time values.
+func parseSQLiteTime(s string) (time.Time, error) {
+	for _, layout := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse time %q", s)
+}
+
+func (rr *reportRepository) ListOpenByItemID(ctx context.Context, itemID int) ([]Report, error) {
+	rows, err := rr.db.QueryContext(ctx, `
+		SELECT id, item_id, reporter_id, reason, comment, status, created_at FROM reports
+		WHERE item_id = ? AND status = ?
+		ORDER BY id`, itemID, ReportStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []Report{}
+	for rows.Next() {
+		var r Report
+		if err := rows.Scan(&r.ID, &r.ItemID, &r.ReporterID, &r.Reason, &r.Comment, &r.Status, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+func (rr *reportRepository) Hide(ctx context.Context, itemID int, decision *ModerationDecision) (bool, error) {
+	tx, err := rr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE items SET status = ? WHERE id = ? AND status = ?", ItemStatusHidden, itemID, ItemStatusOnSale)
+	if err != nil {
+		return false, fmt.Errorf("failed to hide item: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to hide item: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	if err := insertModerationDecision(ctx, tx, decision); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	rr.index.Remove(itemID)
+	return true, nil
+}
+
+func (rr *reportRepository) Resolve(ctx context.Context, itemID int, status ReportStatus, itemStatus ItemStatus, decision *ModerationDecision) error {
+	tx, err := rr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE reports SET status = ? WHERE item_id = ? AND status = ?", status, itemID, ReportStatusOpen)
+	if err != nil {
+		return fmt.Errorf("failed to resolve reports: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to resolve reports: %w", err)
+	}
+	if n == 0 {
+		return errNoOpenReports
+	}
+
+	// a sold item keeps its status, since the order has to be handled separately
+	_, err = tx.ExecContext(ctx, "UPDATE items SET status = ? WHERE id = ? AND status IN (?, ?)",
+		itemStatus, itemID, ItemStatusOnSale, ItemStatusHidden)
+	if err != nil {
+		return fmt.Errorf("failed to update item status: %w", err)
+	}
+	item, err := getItemTx(ctx, tx, itemID)
+	if err != nil {
+		return err
+	}
+	if err := insertModerationDecision(ctx, tx, decision); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	rr.index.Sync(item)
+	return nil
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestReportItem(t *testing.T) {
+	t.Parallel()
+
+	onSale := &Item{ID: 1, Name: "jacket", SellerID: 1, Status: ItemStatusOnSale}
+
+	type wants struct {
+		code int
+	}
+	cases := map[string]struct {
+		userID string
+		form   url.Values
+		// injector is used to inject the expected calls to the mock
+		injector func(i *MockItemRepository, r *MockReportRepository)
+		wants
+	}{
+		"ok: reported": {
+			userID: "2",
+			form:   url.Values{"reason": {"counterfeit"}, "comment": {"looks fake"}},
+			injector: func(i *MockItemRepository, r *MockReportRepository) {
+				i.EXPECT().GetByID(gomock.Any(), 1).Return(onSale, nil)
+				r.EXPECT().Insert(gomock.Any(), &Report{ItemID: 1, ReporterID: 2, Reason: ReportReasonCounterfeit, Comment: "looks fake"}).Return(1, nil)
+			},
+			wants: wants{code: http.StatusCreated},
+		},
+		"ok: hidden at the threshold": {
+			userID: "2",
+			form:   url.Values{"reason": {"spam"}},
+			injector: func(i *MockItemRepository, r *MockReportRepository) {
+				i.EXPECT().GetByID(gomock.Any(), 1).Return(onSale, nil)
+				r.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(reportHideThreshold, nil)
+				r.EXPECT().Hide(gomock.Any(), 1, &ModerationDecision{ItemID: 1, Action: ModerationActionAutoHide, Note: "3 open reports"}).Return(true, nil)
+			},
+			wants: wants{code: http.StatusCreated},
+		},
+		"ng: already reported": {
+			userID: "2",
+			form:   url.Values{"reason": {"spam"}},
+			injector: func(i *MockItemRepository, r *MockReportRepository) {
+				i.EXPECT().GetByID(gomock.Any(), 1).Return(onSale, nil)
+				r.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(0, errAlreadyReported)
+			},
+			wants: wants{code: http.StatusConflict},
+		},
+		"ng: own item": {
+			userID: "1",
+			form:   url.Values{"reason": {"spam"}},
+			injector: func(i *MockItemRepository, r *MockReportRepository) {
+				i.EXPECT().GetByID(gomock.Any(), 1).Return(onSale, nil)
+			},
+			wants: wants{code: http.StatusBadRequest},
+		},
+		"ng: hidden item": {
+			userID: "2",
+			form:   url.Values{"reason": {"spam"}},
+			injector: func(i *MockItemRepository, r *MockReportRepository) {
+				i.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Status: ItemStatusHidden}, nil)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+		"ng: unknown reason": {
+			userID:   "2",
+			form:     url.Values{"reason": {"boring"}},
+			injector: func(i *MockItemRepository, r *MockReportRepository) {},
+			wants:    wants{code: http.StatusUnprocessableEntity},
+		},
+		"ng: no user": {
+			form:     url.Values{"reason": {"spam"}},
+			injector: func(i *MockItemRepository, r *MockReportRepository) {},
+			wants:    wants{code: http.StatusUnauthorized},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockRR := NewMockReportRepository(ctrl)
+			tt.injector(mockIR, mockRR)
+			h := &Handlers{itemRepo: mockIR, reportRepo: mockRR}
+
+			req := httptest.NewRequest("POST", "/items/1/reports", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("item_id", "1")
+			if tt.userID != "" {
+				req.Header.Set(userIDHeader, tt.userID)
+			}
+
+			rr := httptest.NewRecorder()
+			h.ReportItem(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestReportRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	itemRepo := &itemRepository{db: db}
+	repo := &reportRepository{db: db}
+	moderationRepo := &moderationRepository{db: db}
+	ctx := context.Background()
+
+	item := &Item{Name: "jacket", CategoryID: 1, Image: "a.jpg", SellerID: 1}
+	if err := itemRepo.Insert(ctx, item); err != nil {
+		t.Fatalf("failed to insert item: %v", err)
+	}
+
+	for userID, reason := range map[int]ReportReason{2: ReportReasonSpam, 3: ReportReasonSpam, 4: ReportReasonCounterfeit} {
+		if _, err := repo.Insert(ctx, &Report{ItemID: item.ID, ReporterID: userID, Reason: reason}); err != nil {
+			t.Fatalf("failed to insert report: %v", err)
+		}
+	}
+	if _, err := repo.Insert(ctx, &Report{ItemID: item.ID, ReporterID: 2, Reason: ReportReasonOther}); err != errAlreadyReported {
+		t.Fatalf("expected errAlreadyReported, got %v", err)
+	}
+
+	summaries, err := repo.ListOpenSummaries(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to list summaries: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].ReportCount != 3 || summaries[0].Reasons[ReportReasonSpam] != 2 || summaries[0].Reasons[ReportReasonCounterfeit] != 1 {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+
+	hidden, err := repo.Hide(ctx, item.ID, &ModerationDecision{ItemID: item.ID, Action: ModerationActionAutoHide})
+	if err != nil || !hidden {
+		t.Fatalf("expected the item to be hidden, got %v, %v", hidden, err)
+	}
+	// a hidden item is not hidden twice
+	if hidden, err := repo.Hide(ctx, item.ID, &ModerationDecision{ItemID: item.ID, Action: ModerationActionAutoHide}); err != nil || hidden {
+		t.Fatalf("expected the item to stay hidden, got %v, %v", hidden, err)
+	}
+
+	err = repo.Resolve(ctx, item.ID, ReportStatusDismissed, ItemStatusOnSale, &ModerationDecision{ItemID: item.ID, ModeratorID: 9, Action: ModerationActionDismissReports})
+	if err != nil {
+		t.Fatalf("failed to resolve reports: %v", err)
+	}
+	err = repo.Resolve(ctx, item.ID, ReportStatusUpheld, ItemStatusRejected, &ModerationDecision{ItemID: item.ID, ModeratorID: 9, Action: ModerationActionRemove})
+	if err != errNoOpenReports {
+		t.Fatalf("expected errNoOpenReports, got %v", err)
+	}
+
+	got, err := itemRepo.GetByID(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if got.Status != ItemStatusOnSale {
+		t.Errorf("expected the item to be listed again, got %s", got.Status)
+	}
+
+	decisions, err := moderationRepo.ListDecisions(ctx, item.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to list decisions: %v", err)
+	}
+	if len(decisions) != 2 || decisions[0].Action != ModerationActionDismissReports || decisions[0].ModeratorID != 9 || decisions[1].Action != ModerationActionAutoHide {
+		t.Errorf("unexpected decisions: %+v", decisions)
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const maxSavedSearchesPerUser = 50
+
+// AddSavedSearch is a handler to save a search for POST /saved-searches .
+// It accepts the same keyword and filters as GET /search .
+func (s *Handlers) AddSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query, err := parseSearchRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	searches, err := s.savedSearchRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get saved searches: %w", err))
+		return
+	}
+	if len(searches) >= maxSavedSearchesPerUser {
+		writeError(w, r, newAPIError(http.StatusConflict, fmt.Sprintf("at most %d searches can be saved", maxSavedSearchesPerUser)))
+		return
+	}
+
+	search := &SavedSearch{UserID: userID, SearchQuery: *query}
+	if err := s.savedSearchRepo.Insert(ctx, search); err != nil {
+		writeError(w, r, fmt.Errorf("failed to store saved search: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, search)
+}
+
+// GetSavedSearches is a handler to return the saved searches of the user for GET /saved-searches .
+func (s *Handlers) GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	searches, err := s.savedSearchRepo.ListByUserID(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get saved searches: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		SavedSearches []SavedSearch `json:"saved_searches"`
+	}{SavedSearches: searches})
+}
+
+// DeleteSavedSearch is a handler to delete a saved search for DELETE /saved-searches/{saved_search_id} .
+func (s *Handlers) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	searchID, err := strconv.Atoi(r.PathValue("saved_search_id"))
+	if err != nil || searchID < 1 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, "invalid saved search ID"))
+		return
+	}
+
+	if err := s.savedSearchRepo.Delete(r.Context(), searchID, userID); err != nil {
+		writeError(w, r, fmt.Errorf("failed to delete saved search: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notifySavedSearches notifies the users whose saved searches match a newly listed item.
+func (s *Handlers) notifySavedSearches(ctx context.Context, item *Item, category string) error {
+	searches, err := s.savedSearchRepo.ListMatching(ctx, item, category)
+	if err != nil {
+		return err
+	}
+
+	// a user with several matching searches is notified only once
+	notified := make(map[int]bool)
+	var errs []error
+	for _, search := range searches {
+		if notified[search.UserID] {
+			continue
+		}
+		notified[search.UserID] = true
+
+		err := s.notifier.Notify(ctx, &Notification{
+			UserID:  search.UserID,
+			Type:    NotificationSavedSearchMatch,
+			ItemID:  item.ID,
+			Message: fmt.Sprintf("New item matching %q: %s", search.Keyword, item.Name),
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var errSavedSearchNotFound = errors.New("saved search not found")
+
+// SavedSearch is a search a user wants to be notified about when matching items are listed.
+type SavedSearch struct {
+	ID     int `db:"id" json:"id"`
+	UserID int `db:"user_id" json:"user_id"`
+	SearchQuery
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// SavedSearchRepository is an interface to manage saved searches.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type SavedSearchRepository interface {
+	Insert(ctx context.Context, search *SavedSearch) error
+	ListByUserID(ctx context.Context, userID int) ([]SavedSearch, error)
+	// Delete deletes the saved search of the user and returns errSavedSearchNotFound if there is none.
+	Delete(ctx context.Context, searchID, userID int) error
+	// ListMatching returns the saved searches of the other users that match the item,
+	// using the same rules as ItemRepository.Search.
+	ListMatching(ctx context.Context, item *Item, category string) ([]SavedSearch, error)
+}
+
+// savedSearchRepository is an implementation of SavedSearchRepository
+type savedSearchRepository struct {
+	db *sql.DB
+}
+
+// NewSavedSearchRepository creates a new savedSearchRepository.
+func NewSavedSearchRepository(database *sql.DB) SavedSearchRepository {
+	return &savedSearchRepository{db: database}
+}
+
+const savedSearchColumns = "id, user_id, keyword, category, min_price, max_price, attributes, created_at"
+
+func scanSavedSearch(row interface{ Scan(dest ...any) error }, s *SavedSearch) error {
+	var attrs string
+	if err := row.Scan(&s.ID, &s.UserID, &s.Keyword, &s.Category, &s.MinPrice, &s.MaxPrice, &attrs, &s.CreatedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(attrs), &s.Attributes)
+}
+
+func (ss *savedSearchRepository) Insert(ctx context.Context, search *SavedSearch) error {
+	attrs, err := json.Marshal(search.Attributes)
+	if err != nil {
+		return fmt.Errorf("failed to encode attributes: %w", err)
+	}
+	if search.Attributes == nil {
+		attrs = []byte("{}")
+	}
+
+	search.CreatedAt = time.Now().UTC()
+	res, err := ss.db.ExecContext(ctx, `
+		INSERT INTO saved_searches (user_id, keyword, category, min_price, max_price, attributes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		search.UserID, search.Keyword, search.Category, search.MinPrice, search.MaxPrice, string(attrs), search.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert saved search: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get saved search id: %w", err)
+	}
+	search.ID = int(id)
+	return nil
+}
+
+func (ss *savedSearchRepository) ListByUserID(ctx context.Context, userID int) ([]SavedSearch, error) {
+	return ss.list(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches WHERE user_id = ? ORDER BY id", userID)
+}
+
+func (ss *savedSearchRepository) Delete(ctx context.Context, searchID, userID int) error {
+	res, err := ss.db.ExecContext(ctx, "DELETE FROM saved_searches WHERE id = ? AND user_id = ?", searchID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	if n == 0 {
+		return errSavedSearchNotFound
+	}
+	return nil
+}
+
+func (ss *savedSearchRepository) ListMatching(ctx context.Context, item *Item, category string) ([]SavedSearch, error) {
+	return ss.list(ctx, "SELECT "+savedSearchColumns+` FROM saved_searches
+		WHERE user_id <> ?
+		  AND ? LIKE '%' || keyword || '%'
+		  AND (category = '' OR category = ?)
+		  AND (min_price = 0 OR min_price <= ?)
+		  AND (max_price = 0 OR max_price >= ?)
+		  AND NOT EXISTS (
+			SELECT 1 FROM json_each(saved_searches.attributes) AS filter
+			WHERE NOT EXISTS (
+				SELECT 1 FROM item_attributes
+				WHERE item_attributes.item_id = ? AND item_attributes.name = filter.key AND item_attributes.value = filter.value
+			)
+		  )`,
+		item.SellerID, item.Name, category, item.Price, item.Price, item.ID)
+}
+
+func (ss *savedSearchRepository) list(ctx context.Context, query string, args ...any) ([]SavedSearch, error) {
+	rows, err := ss.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []SavedSearch{}
+	for rows.Next() {
+		var s SavedSearch
+		if err := scanSavedSearch(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate saved searches: %w", err)
+	}
+
+	return searches, nil
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseSearchRequest(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		query *SearchQuery
+		err   bool
+	}
+	cases := map[string]struct {
+		rawQuery string
+		wants
+	}{
+		"ok: keyword only": {
+			rawQuery: "keyword=switch",
+			wants:    wants{query: &SearchQuery{Keyword: "switch"}},
+		},
+		"ok: keyword and filters": {
+			rawQuery: "keyword=nintendo+switch&category=game&max_price=20000",
+			wants:    wants{query: &SearchQuery{Keyword: "nintendo switch", Category: "game", MaxPrice: 20000}},
+		},
+		"ng: keyword is missing": {
+			rawQuery: "max_price=20000",
+			wants:    wants{err: true},
+		},
+		"ng: min_price is greater than max_price": {
+			rawQuery: "keyword=switch&min_price=30000&max_price=20000",
+			wants:    wants{err: true},
+		},
+		"ng: negative price": {
+			rawQuery: "keyword=switch&min_price=-1",
+			wants:    wants{err: true},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "/search?"+tt.rawQuery, nil)
+			got, err := parseSearchRequest(req)
+			if err != nil {
+				if !tt.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if tt.err {
+				t.Fatalf("expected an error, got %+v", got)
+			}
+			if diff := cmp.Diff(tt.wants.query, got); diff != "" {
+				t.Errorf("unexpected query (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// recordingNotifier is a Notifier keeping the notifications in memory.
+type recordingNotifier struct {
+	notifications []*Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification *Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func TestNotifySavedSearches(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockSSR := NewMockSavedSearchRepository(ctrl)
+	item := &Item{ID: 8, Name: "Nintendo Switch", SellerID: 1, Price: 18000}
+	mockSSR.EXPECT().ListMatching(gomock.Any(), item, "game").Return([]SavedSearch{
+		{ID: 1, UserID: 2, SearchQuery: SearchQuery{Keyword: "switch"}},
+		{ID: 2, UserID: 2, SearchQuery: SearchQuery{Keyword: "nintendo", MaxPrice: 20000}},
+		{ID: 3, UserID: 3, SearchQuery: SearchQuery{Keyword: "nintendo switch"}},
+	}, nil).Times(1)
+
+	notifier := &recordingNotifier{}
+	h := &Handlers{savedSearchRepo: mockSSR, notifier: notifier}
+
+	if err := h.notifySavedSearches(context.Background(), item, "game"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var gotUsers []int
+	for _, n := range notifier.notifications {
+		if n.ItemID != item.ID || n.Type != NotificationSavedSearchMatch {
+			t.Errorf("unexpected notification: %+v", n)
+		}
+		gotUsers = append(gotUsers, n.UserID)
+	}
+	if diff := cmp.Diff([]int{2, 3}, gotUsers); diff != "" {
+		t.Errorf("unexpected notified users (-want +got):\n%s", diff)
+	}
+}
+
+func TestSavedSearchRepositoryListMatching(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	repo := &savedSearchRepository{db: db}
+	ctx := context.Background()
+	for _, s := range []*SavedSearch{
+		{UserID: 2, SearchQuery: SearchQuery{Keyword: "switch", MaxPrice: 20000}},
+		{UserID: 3, SearchQuery: SearchQuery{Keyword: "switch", MaxPrice: 15000}},
+		{UserID: 4, SearchQuery: SearchQuery{Keyword: "SWITCH", Category: "game"}},
+		{UserID: 5, SearchQuery: SearchQuery{Keyword: "switch", Category: "book"}},
+		{UserID: 6, SearchQuery: SearchQuery{Keyword: "playstation"}},
+		{UserID: 1, SearchQuery: SearchQuery{Keyword: "switch"}},
+	} {
+		if err := repo.Insert(ctx, s); err != nil {
+			t.Fatalf("failed to insert saved search: %v", err)
+		}
+	}
+
+	got, err := repo.ListMatching(ctx, &Item{Name: "Nintendo Switch", SellerID: 1, Price: 18000}, "game")
+	if err != nil {
+		t.Fatalf("failed to list matching searches: %v", err)
+	}
+
+	var gotUsers []int
+	for _, s := range got {
+		gotUsers = append(gotUsers, s.UserID)
+	}
+	sort.Ints(gotUsers)
+	if diff := cmp.Diff([]int{2, 4}, gotUsers); diff != "" {
+		t.Errorf("unexpected matching users (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Scheduler runs a task periodically in a background goroutine.
+type Scheduler struct {
+	interval time.Duration
+	task     func(ctx context.Context)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a Scheduler running task every interval.
+func NewScheduler(interval time.Duration, task func(ctx context.Context)) *Scheduler {
+	return &Scheduler{interval: interval, task: task}
+}
+
+// Start starts running the task. The first run happens after one interval.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.run(ctx)
+			}
+		}
+	}()
+}
+
+// run runs the task, keeping a panicking task from stopping the scheduler.
+func (s *Scheduler) run(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "scheduled task panicked", "panic", r)
+		}
+	}()
+	s.task(ctx)
+}
+
+// Stop cancels the context passed to the task and waits for the running task to return.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
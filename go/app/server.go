@@ -1,76 +1,375 @@
 package app
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// readHeaderTimeout limits how long a client may take to send the request headers.
+const readHeaderTimeout = 10 * time.Second
+
 type Server struct {
-	// Port is the port number to listen on.
-	Port string
-	// ImageDirPath is the path to the directory storing images.
-	ImageDirPath string
+	// Config is the configuration loaded with LoadConfig.
+	Config Config
+
+	// onShutdown is called when the server starts shutting down, if it is not nil.
+	onShutdown func()
 }
 
 // Run is a method to start the server.
-// This method returns 0 if the server started successfully, and 1 otherwise.
+// The server is shut down gracefully on SIGINT or SIGTERM.
+// This method returns 0 if the server stopped gracefully, and 1 otherwise.
 func (s Server) Run() int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := s.RunContext(ctx); err != nil {
+		slog.ErrorContext(ctx, "server stopped with an error", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// RunContext listens on Config.Port and serves on it until ctx is canceled, as Serve does.
+func (s Server) RunContext(ctx context.Context) error {
+	if err := s.Config.Validate(); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", ":"+s.Config.Port)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves on the listener until ctx is canceled. It then stops accepting connections,
+// waits up to Config.ShutdownTimeout for the in-flight requests, stops the background
+// workers and closes the database, in this order. The listener is closed when Serve returns.
+func (s Server) Serve(ctx context.Context, ln net.Listener) error {
+	defer ln.Close()
+	if err := s.Config.Validate(); err != nil {
+		return err
+	}
+
 	// set up logger
-	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	level, _ := s.Config.slogLevel()
+	logger := newLogger(os.Stderr, level)
 	slog.SetDefault(logger)
-	// STEP 4-6: set the log level to DEBUG
-	slog.SetLogLoggerLevel(slog.LevelInfo)
 
 	// set up CORS settings
-	frontURL, found := os.LookupEnv("FRONT_URL")
-	if !found {
-		frontURL = "http://localhost:3000"
+	corsPolicy := NewCORSPolicy(append([]string{s.Config.FrontURL}, s.Config.CORSOrigins...), s.Config.CORSAllowCredentials)
+
+	adminIDs := make(map[int]bool, len(s.Config.AdminUserIDs))
+	for _, id := range s.Config.AdminUserIDs {
+		adminIDs[id] = true
 	}
 
+	// set up tracing, which is shut down last to flush the spans of the workers
+	tp, shutdownTracing, err := setupTracing(ctx, s.Config.TraceExporter, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "failed to flush spans", "error", err)
+		}
+	}()
+
 	// STEP 5-1: set up the database connection
-	db, err := InitDB("db/mercari.sqlite3")
+	db, err := InitDB(s.Config.DBPath)
 	if err != nil {
-		slog.Error("failed to initialize database", "error", err)
-		return 1
+		return fmt.Errorf("failed to initialize database: %w", err)
 	}
+	// the deferred calls below run in reverse, so the database is closed after every worker has stopped
+	defer func() {
+		if err := db.Close(); err != nil {
+			slog.ErrorContext(ctx, "failed to close database", "error", err)
+		}
+	}()
 
 	// set up handlers
-	itemRepo := NewItemRepository(db)
-	h := &Handlers{imgDirPath: s.ImageDirPath, itemRepo: itemRepo}
+	metrics := NewMetrics()
+	similarIndex := NewSimilarityIndex()
+	itemRepo := instrumentItemRepository(NewItemRepository(db, similarIndex), metrics)
+	commentRepo := NewCommentRepository(db)
+	offerRepo := NewOfferRepository(db)
+	orderRepo := NewOrderRepository(db, similarIndex)
+	conversationRepo := NewConversationRepository(db)
+	ratingRepo := NewRatingRepository(db)
+	followRepo := NewFollowRepository(db)
+	savedSearchRepo := NewSavedSearchRepository(db)
+	notificationRepo := NewNotificationRepository(db)
+	watchRepo := NewWatchRepository(db)
+	attributeSchemaRepo := NewAttributeSchemaRepository(db)
+	moderationRepo := NewModerationRepository(db, similarIndex)
+	reportRepo := NewReportRepository(db, similarIndex)
+	eventRepo := NewEventRepository(db)
+	analyticsRepo := NewAnalyticsRepository(db)
+	healthRepo := NewHealthRepository(db)
+
+	// the index is kept in memory, so the items on sale are indexed at every start
+	listed, err := itemRepo.GetAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to build similarity index: %w", err)
+	}
+	for k := range listed {
+		similarIndex.Sync(&listed[k])
+	}
+
+	// background jobs such as notification fan-outs
+	jobQueue := NewJobQueue(1000, 4)
+	jobQueue.Start(context.Background())
+	defer jobQueue.Stop()
+
+	// item views are written in batches
+	viewRecorder := NewViewRecorder(analyticsRepo)
+	viewRecorder.Start(context.Background())
+	defer viewRecorder.Stop()
+
+	h := &Handlers{
+		imgDirPath:  s.Config.ImageDirPath,
+		itemRepo:    itemRepo,
+		commentRepo: commentRepo,
+		offerRepo:   offerRepo,
+		orderRepo:   orderRepo,
+
+		conversationRepo: conversationRepo,
+		ratingRepo:       ratingRepo,
+		followRepo:       followRepo,
+		savedSearchRepo:  savedSearchRepo,
+		notificationRepo: notificationRepo,
+		notifier:         Notifiers{NewInAppNotifier(notificationRepo)},
+		watchRepo:        watchRepo,
+		jobQueue:         jobQueue,
+
+		attributeSchemaRepo: attributeSchemaRepo,
+		moderationRepo:      moderationRepo,
+		reportRepo:          reportRepo,
+		similarIndex:        similarIndex,
+		eventRepo:           eventRepo,
+		trending:            &TrendingCache{},
+		analyticsRepo:       analyticsRepo,
+		viewRecorder:        viewRecorder,
+		searchClicks:        newClickDeduper(searchClickDedupWindow),
+		healthRepo:          healthRepo,
+		metrics:             metrics,
+		adminIDs:            adminIDs,
+	}
+
+	// publish the scheduled drafts
+	publisher := NewScheduler(publishInterval, h.publishScheduledItems)
+	publisher.Start(context.Background())
+	defer publisher.Stop()
+
+	// rank the trending items now and then periodically
+	h.refreshTrending(context.Background())
+	trendingRanker := NewScheduler(trendingInterval, h.refreshTrending)
+	trendingRanker.Start(context.Background())
+	defer trendingRanker.Stop()
+
+	// forget the clients idle long enough to be limited from scratch
+	rateLimitStore := NewMemoryRateLimitStore()
+	rateLimitPruner := NewScheduler(rateLimitPruneInterval, rateLimitStore.Prune)
+	rateLimitPruner.Start(context.Background())
+	defer rateLimitPruner.Stop()
 
 	// set up routes
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /", h.Hello)
+	mux.HandleFunc("GET /{$}", h.Hello)
+	mux.HandleFunc("GET /healthz", h.Healthz)
+	mux.HandleFunc("GET /readyz", h.Readyz)
+	mux.HandleFunc("GET /version", h.Version)
+	mux.Handle("GET /metrics", metrics.Handler())
 	mux.HandleFunc("POST /items", h.AddItem)
 	mux.HandleFunc("GET /items", h.GetItems) //add in 4-3
 	mux.HandleFunc("GET /images/{filename}", h.GetImage)
 	mux.HandleFunc("GET /items/{item_id}", h.GetItem)
 	mux.HandleFunc("GET /search", h.Search) //add in STEP5
+	mux.HandleFunc("GET /items/{item_id}/comments", h.GetComments)
+	mux.HandleFunc("POST /items/{item_id}/comments", h.AddComment)
+	mux.HandleFunc("DELETE /items/{item_id}/comments/{comment_id}", h.DeleteComment)
+	mux.HandleFunc("GET /items/{item_id}/offers", h.GetOffers)
+	mux.HandleFunc("POST /items/{item_id}/offers", h.AddOffer)
+	mux.HandleFunc("POST /offers/{offer_id}/accept", h.AcceptOffer)
+	mux.HandleFunc("POST /offers/{offer_id}/reject", h.RejectOffer)
+	mux.HandleFunc("POST /offers/{offer_id}/counter", h.CounterOffer)
+	mux.HandleFunc("POST /items/{item_id}/purchase", h.PurchaseItem)
+	mux.HandleFunc("GET /orders/{order_id}", h.GetOrder)
+	mux.HandleFunc("GET /orders/{order_id}/conversation", h.GetOrderConversation)
+	mux.HandleFunc("GET /conversations", h.GetConversations)
+	mux.HandleFunc("GET /conversations/{conversation_id}/messages", h.GetMessages)
+	mux.HandleFunc("POST /conversations/{conversation_id}/messages", h.AddMessage)
+	mux.HandleFunc("POST /orders/{order_id}/complete", h.CompleteOrder)
+	mux.HandleFunc("POST /orders/{order_id}/ratings", h.AddRating)
+	mux.HandleFunc("GET /sellers/{seller_id}", h.GetSellerProfile)
+	mux.HandleFunc("POST /sellers/{seller_id}/follow", h.FollowSeller)
+	mux.HandleFunc("DELETE /sellers/{seller_id}/follow", h.UnfollowSeller)
+	mux.HandleFunc("GET /feed", h.GetFeed)
+	mux.HandleFunc("GET /saved-searches", h.GetSavedSearches)
+	mux.HandleFunc("POST /saved-searches", h.AddSavedSearch)
+	mux.HandleFunc("DELETE /saved-searches/{saved_search_id}", h.DeleteSavedSearch)
+	mux.HandleFunc("GET /notifications", h.GetNotifications)
+	mux.HandleFunc("PATCH /items/{item_id}", h.UpdateItem)
+	mux.HandleFunc("GET /items/{item_id}/price-history", h.GetPriceHistory)
+	mux.HandleFunc("POST /items/{item_id}/watch", h.WatchItem)
+	mux.HandleFunc("DELETE /items/{item_id}/watch", h.UnwatchItem)
+	mux.HandleFunc("GET /watchlist", h.GetWatchlist)
+	mux.HandleFunc("GET /drafts", h.GetDrafts)
+	mux.HandleFunc("POST /items/{item_id}/publish", h.PublishItem)
+	mux.HandleFunc("GET /categories/{category_id}/attributes", h.GetCategoryAttributes)
+	mux.HandleFunc("GET /admin/moderation/rules", h.GetModerationRules)
+	mux.HandleFunc("POST /admin/moderation/rules", h.AddModerationRule)
+	mux.HandleFunc("DELETE /admin/moderation/rules/{rule_id}", h.DeleteModerationRule)
+	mux.HandleFunc("GET /admin/moderation/reviews", h.GetModerationReviews)
+	mux.HandleFunc("POST /admin/moderation/reviews/{review_id}/approve", h.ApproveModerationReview)
+	mux.HandleFunc("POST /admin/moderation/reviews/{review_id}/reject", h.RejectModerationReview)
+	mux.HandleFunc("GET /admin/moderation/decisions", h.GetModerationDecisions)
+	mux.HandleFunc("POST /items/{item_id}/reports", h.ReportItem)
+	mux.HandleFunc("GET /admin/reports", h.GetReports)
+	mux.HandleFunc("GET /admin/reports/{item_id}", h.GetItemReports)
+	mux.HandleFunc("POST /admin/reports/{item_id}/resolve", h.ResolveReports)
+	mux.HandleFunc("GET /price-suggestion", h.GetPriceSuggestion)
+	mux.HandleFunc("GET /items/{item_id}/similar", h.GetSimilarItems)
+	mux.HandleFunc("GET /items/trending", h.GetTrendingItems)
+	mux.HandleFunc("POST /items/{item_id}/search-clicks", h.RecordSearchClick)
+	mux.HandleFunc("GET /items/{item_id}/analytics", h.GetItemAnalytics)
+	mux.HandleFunc("GET /sellers/{seller_id}/analytics", h.GetSellerAnalytics)
 
 	// start the server
-	slog.Info("http server started on", "port", s.Port)
-	err = http.ListenAndServe(":"+s.Port, simpleCORSMiddleware(simpleLoggerMiddleware(mux), frontURL, []string{"GET", "HEAD", "POST", "OPTIONS"}))
-	if err != nil {
-		slog.Error("failed to start server: ", "error", err)
-		return 1
+	srv := &http.Server{
+		Handler:           requestIDMiddleware(tracingMiddleware(requestLoggerMiddleware(metricsMiddleware(corsMiddleware(rateLimitMiddleware(mux, rateLimitStore, routeRateLimits), mux, corsPolicy), metrics), logger), tp)),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	if s.onShutdown != nil {
+		srv.RegisterOnShutdown(s.onShutdown)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+	slog.InfoContext(ctx, "http server started on", "addr", ln.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
 	}
 
-	return 0
+	// drain the in-flight requests, such as uploads, before the workers and the database are stopped
+	slog.InfoContext(ctx, "shutting down server", "timeout", s.Config.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.Config.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// cut off the requests still running
+		srv.Close()
+		return fmt.Errorf("failed to drain requests: %w", err)
+	}
+	slog.InfoContext(ctx, "http server stopped")
+	return nil
 }
 
 type Handlers struct {
 	// imgDirPath is the path to the directory storing images.
-	imgDirPath string
-	itemRepo   ItemRepository
+	imgDirPath  string
+	itemRepo    ItemRepository
+	commentRepo CommentRepository
+	offerRepo   OfferRepository
+	orderRepo   OrderRepository
+
+	conversationRepo ConversationRepository
+	ratingRepo       RatingRepository
+	followRepo       FollowRepository
+	savedSearchRepo  SavedSearchRepository
+	notificationRepo NotificationRepository
+	notifier         Notifier
+	watchRepo        WatchRepository
+	jobQueue         *JobQueue
+
+	attributeSchemaRepo AttributeSchemaRepository
+	moderationRepo      ModerationRepository
+	reportRepo          ReportRepository
+	similarIndex        *SimilarityIndex
+	eventRepo           EventRepository
+	trending            *TrendingCache
+	analyticsRepo       AnalyticsRepository
+	viewRecorder        *ViewRecorder
+	searchClicks        *clickDeduper
+	healthRepo          HealthRepository
+	metrics             *Metrics
+	// adminIDs are the users allowed to use the admin endpoints.
+	adminIDs map[int]bool
+}
+
+// userIDHeader is the header carrying the ID of the user sending the request.
+// There is no authentication yet, so the value is trusted as is.
+const userIDHeader = "X-User-ID"
+
+var (
+	errUserIDRequired = errors.New("user ID is required")
+	errInvalidUserID  = errors.New("invalid user ID")
+)
+
+// parseUserID returns the ID of the user sending the request.
+func parseUserID(r *http.Request) (int, error) {
+	v := r.Header.Get(userIDHeader)
+	if v == "" {
+		return 0, errUserIDRequired
+	}
+	userID, err := strconv.Atoi(v)
+	if err != nil || userID < 1 {
+		return 0, errInvalidUserID
+	}
+	return userID, nil
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode response: ", "error", err)
+	}
+}
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// parsePagination parses the limit and offset query parameters.
+func parsePagination(r *http.Request) (limit, offset int, err error) {
+	limit, offset = defaultPageLimit, 0
+
+	var v validator
+	q := r.URL.Query()
+	if s := q.Get("limit"); s != "" {
+		limit = v.intRange("limit", s, 1, maxPageLimit)
+	}
+	if s := q.Get("offset"); s != "" {
+		offset = v.intRange("offset", s, 0, noLimit)
+	}
+	if err := v.err(); err != nil {
+		return 0, 0, err
+	}
+	return limit, offset, nil
 }
 
 type HelloResponse struct {
@@ -79,18 +378,16 @@ type HelloResponse struct {
 
 // Hello is a handler to return a Hello, world! message for GET / .
 func (s *Handlers) Hello(w http.ResponseWriter, r *http.Request) {
-	resp := HelloResponse{Message: "Hello, world!"}
-	err := json.NewEncoder(w).Encode(resp)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	writeJSON(w, http.StatusOK, HelloResponse{Message: "Hello, world!"})
 }
 
 type AddItemRequest struct {
 	Name     string `json:"name"`
 	Category string `json:"category"`   // STEP 4-2: add a category field
 	Image    []byte `json:"image_name"` // STEP 4-4: add an image field
+	Price    int    `json:"price"`
+	// Attributes are sent as attr.<name> form values and validated against the schema of the category.
+	Attributes map[string]string `json:"attributes"`
 }
 
 type AddItemResponse struct {
@@ -107,83 +404,352 @@ func parseAddItemRequest(r *http.Request) (*AddItemRequest, []byte, string, erro
 	// STEP 4-4: add an image field
 	err := r.ParseMultipartForm(10 << 20)
 	if err != nil {
-		fmt.Println("Failed to parse form data:", err)
 		return nil, nil, "", fmt.Errorf("failed to parse form data: %w", err)
 	}
 
-	file, header, err := r.FormFile("image")
-	if err != nil {
-		return nil, nil, "", errors.New("image file is required")
-	}
-	defer file.Close()
-
 	// validate the request
-	if req.Name == "" {
-		return nil, nil, "", errors.New("name is required")
+	var v validator
+	if v.required("name", req.Name) {
+		v.maxLength("name", req.Name, maxItemNameLength)
 	}
 
 	// STEP 4-2: validate the category field
-	if req.Category == "" {
-		return nil, nil, "", errors.New("category is required")
+	v.required("category", req.Category)
+
+	// price is optional for backward compatibility
+	if s := r.FormValue("price"); s != "" {
+		req.Price = v.intRange("price", s, 0, noLimit)
 	}
 
+	req.Attributes = parseAttributes(&v, r.PostForm)
+
 	// STEP 4-4: validate the image field
-	imageData, err := io.ReadAll(file)
-	if err != nil {
-		return nil, nil, "", errors.New("imagename is required")
+	var imageData []byte
+	var filename string
+	file, header, err := r.FormFile("image")
+	if v.check(err == nil, "image", "image is required") {
+		defer file.Close()
+		imageData = v.image("image", file, header)
+		filename = header.Filename
 	}
 
-	return req, imageData, header.Filename, nil
+	if err := v.err(); err != nil {
+		return nil, nil, "", err
+	}
+	return req, imageData, filename, nil
 }
 
 // AddItem is a handler to add a new item for POST /items .
+// With draft=true, the item is saved as a draft instead of being listed.
 func (s *Handlers) AddItem(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	if isDraftRequest(r) {
+		s.addDraft(w, r)
+		return
+	}
+
+	_, span := startSpan(ctx, "parseAddItemRequest")
 	req, imageData, filename, err := parseAddItemRequest(r)
+	endSpan(span, err)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, badRequest(err))
 		return
 	}
 
-	// STEP 4-4: uncomment on adding an implementation to store an image
-	filePath, err := s.storeImage(imageData)
-	if err != nil {
-		slog.Error("failed to store image: ", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// the seller is optional until every client sends the user ID
+	sellerID, err := parseUserID(r)
+	if err != nil && !errors.Is(err, errUserIDRequired) {
+		writeError(w, r, badRequest(err))
 		return
 	}
 
 	//get category_id
 	categoryID, err := s.itemRepo.GetCategoryID(ctx, req.Category)
 	if err != nil {
-		http.Error(w, "failed to get category ID", http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("failed to get category ID: %w", err))
 		return
 	}
 
 	item := &Item{
 		Name:       req.Name,
 		CategoryID: categoryID, // STEP 4-2: add a category field
-		Image:      filePath,   // STEP 4-4: add an image field
+		SellerID:   sellerID,
+		Price:      req.Price,
+		Attributes: req.Attributes,
+	}
+	if err := s.validateItemAttributes(ctx, item, true); err != nil {
+		writeError(w, r, fmt.Errorf("failed to validate attributes: %w", err))
+		return
+	}
 
+	// flagged items are stored hidden so that they are never listed before a review
+	reasons, err := s.checkModeration(ctx, item)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to moderate item: %w", err))
+		return
 	}
+
+	// the image is stored only once the request has been accepted, so that a rejected one leaves no file
+	// STEP 4-4: uncomment on adding an implementation to store an image
+	item.Image, err = s.storeImage(ctx, imageData) // STEP 4-4: add an image field
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to store image: %w", err))
+		return
+	}
+
 	message := fmt.Sprintf("item received: %s,%s, %s", item.Name, req.Category, filename)
-	slog.Info(message)
+	slog.InfoContext(ctx, message)
 
 	// STEP 4-2: add an implementation to store an image
-	err = s.itemRepo.Insert(ctx, item)
+	code := http.StatusOK
+	if len(reasons) > 0 {
+		if _, err := s.itemRepo.InsertQuarantined(ctx, item, reasons); err != nil {
+			writeError(w, r, fmt.Errorf("failed to store item: %w", err))
+			return
+		}
+		message += " (held for review)"
+		code = http.StatusAccepted
+	} else {
+		if err := s.itemRepo.Insert(ctx, item); err != nil {
+			writeError(w, r, fmt.Errorf("failed to store item: %w", err))
+			return
+		}
+		if err := s.notifySavedSearches(ctx, item, req.Category); err != nil {
+			// notifications are best effort and must not fail the listing
+			slog.ErrorContext(ctx, "failed to notify saved searches: ", "error", err)
+		}
+	}
+
+	writeJSON(w, code, &AddItemResponse{Message: message})
+}
+
+type UpdateItemRequest struct {
+	ItemID   int
+	Name     *string `json:"name"`
+	Price    *int    `json:"price"`
+	Category *string `json:"category"`
+	Image    []byte  `json:"image_name"`
+	// Attributes are merged into the attributes of the draft.
+	Attributes map[string]string `json:"attributes"`
+	// SetPublishAt tells whether publish_at is in the request. An empty publish_at cancels the schedule.
+	SetPublishAt bool       `json:"-"`
+	PublishAt    *time.Time `json:"publish_at"`
+}
+
+// parseUpdateItemRequest parses and validates the request to update an item.
+// Only the fields present in the request are updated.
+func parseUpdateItemRequest(r *http.Request) (*UpdateItemRequest, error) {
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	// the image of a draft can be sent as multipart form data
+	if err := r.ParseMultipartForm(10 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, fmt.Errorf("failed to parse form data: %w", err)
+	}
+
+	req := &UpdateItemRequest{ItemID: itemID}
+	var v validator
+	if r.PostForm.Has("name") {
+		name := r.PostForm.Get("name")
+		if v.required("name", name) && v.maxLength("name", name, maxItemNameLength) {
+			req.Name = &name
+		}
+	}
+	if r.PostForm.Has("price") {
+		price := v.intRange("price", r.PostForm.Get("price"), 0, noLimit)
+		req.Price = &price
+	}
+	if r.PostForm.Has("category") {
+		category := r.PostForm.Get("category")
+		if v.required("category", category) {
+			req.Category = &category
+		}
+	}
+	if r.PostForm.Has("publish_at") {
+		req.SetPublishAt = true
+		if s := r.PostForm.Get("publish_at"); s != "" {
+			req.PublishAt = parsePublishAt(&v, s)
+		}
+	}
+	req.Image = parseOptionalImage(&v, r)
+	req.Attributes = parseAttributes(&v, r.PostForm)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	// validate the request
+	if req.Name == nil && req.Price == nil && req.Category == nil && req.Image == nil && req.Attributes == nil && !req.SetPublishAt {
+		return nil, errors.New("nothing to update")
+	}
+
+	return req, nil
+}
+
+// UpdateItem is a handler for the seller to update an item for PATCH /items/{item_id} .
+// When the price is lowered, the watchers of the item are notified in the background.
+// The category and attributes are validated against the schema of the category, requiring every
+// attribute unless the item is an unscheduled draft. Drafts can also change their image and schedule
+// when they are published.
+func (s *Handlers) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	req, err := parseUpdateItemRequest(r)
 	if err != nil {
-		slog.Error("failed to store item: ", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, req.ItemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+	if item.SellerID != userID {
+		writeError(w, r, newAPIError(http.StatusForbidden, "only the seller can update the item"))
+		return
+	}
+	isDraft := item.Status == ItemStatusDraft
+	if item.Status != ItemStatusOnSale && !isDraft {
+		writeError(w, r, errItemNotOnSale)
+		return
+	}
+	if !isDraft && (req.Image != nil || req.SetPublishAt) {
+		writeError(w, r, newAPIError(http.StatusBadRequest, "image and publish_at can only be changed on drafts"))
+		return
+	}
+
+	oldPrice := item.Price
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Price != nil {
+		item.Price = *req.Price
+	}
+	if req.SetPublishAt {
+		item.PublishAt = req.PublishAt
+	}
+	if req.Category != nil {
+		item.CategoryID, err = s.itemRepo.GetCategoryID(ctx, *req.Category)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("failed to get category ID: %w", err))
+			return
+		}
+	}
+
+	for name, value := range req.Attributes {
+		if item.Attributes == nil {
+			item.Attributes = make(map[string]string)
+		}
+		item.Attributes[name] = value
+	}
+
+	// a scheduled draft must be ready to be listed
+	if missing := missingDraftFields(item, req.Image != nil); isDraft && item.PublishAt != nil && len(missing) > 0 {
+		writeError(w, r, errIncompleteDraft(missing))
 		return
 	}
+	// a listed item must keep every attribute its category requires
+	if isDraft || req.Category != nil || req.Attributes != nil {
+		if err := s.validateItemAttributes(ctx, item, !isDraft || item.PublishAt != nil); err != nil {
+			writeError(w, r, fmt.Errorf("failed to validate attributes: %w", err))
+			return
+		}
+	}
 
-	resp := &AddItemResponse{Message: message}
-	err = json.NewEncoder(w).Encode(resp)
+	// the image is stored only once the update has been accepted, so that a rejected one leaves no file
+	if req.Image != nil {
+		item.Image, err = s.storeImage(ctx, req.Image)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("failed to store image: %w", err))
+			return
+		}
+	}
+
+	// drafts are moderated when they are published, and a flagged listed item is
+	// quarantined in the transaction updating it, so it is never listed with the flagged values
+	var reasons []string
+	if !isDraft && (req.Name != nil || req.Category != nil || req.Attributes != nil) {
+		reasons, err = s.checkModeration(ctx, item)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("failed to moderate item: %w", err))
+			return
+		}
+	}
+	if len(reasons) > 0 {
+		if _, err := s.itemRepo.UpdateQuarantined(ctx, item, reasons); err != nil {
+			writeError(w, r, fmt.Errorf("failed to update item: %w", err))
+			return
+		}
+		slog.InfoContext(ctx, "item quarantined", "item_id", item.ID, "reasons", reasons)
+		writeJSON(w, http.StatusAccepted, item)
+		return
+	}
+	if err := s.itemRepo.Update(ctx, item); err != nil {
+		writeError(w, r, fmt.Errorf("failed to update item: %w", err))
+		return
+	}
+
+	if !isDraft && item.Price < oldPrice {
+		updated := *item
+		queued := s.jobQueue.Enqueue(func(ctx context.Context) {
+			if err := s.notifyPriceDrop(ctx, &updated, oldPrice); err != nil {
+				slog.ErrorContext(ctx, "failed to notify price drop: ", "error", err, "item_id", updated.ID)
+			}
+		})
+		if !queued {
+			slog.ErrorContext(ctx, "job queue is full, price drop notification dropped", "item_id", item.ID)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
+// GetPriceHistory is a handler to return the price changes of an item for GET /items/{item_id}/price-history .
+func (s *Handlers) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := parseGetItemRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, badRequest(err))
+		return
+	}
+	// anonymous users can see the price history of any item they can see
+	userID, err := parseUserID(r)
+	if err != nil && !errors.Is(err, errUserIDRequired) {
+		writeError(w, r, badRequest(err))
 		return
 	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+
+	history, err := s.itemRepo.GetPriceHistory(ctx, itemID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get price history: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		PriceHistory []PriceChange `json:"price_history"`
+	}{PriceHistory: history})
 }
 
 // GetItems is a handler to return resistered items
@@ -193,7 +759,7 @@ func (s *Handlers) GetItems(w http.ResponseWriter, r *http.Request) {
 	// get the data
 	items, err := s.itemRepo.GetAll(ctx)
 	if err != nil {
-		http.Error(w, "filed to retrieve items", http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("failed to retrieve items: %w", err))
 		return
 	}
 
@@ -202,24 +768,29 @@ func (s *Handlers) GetItems(w http.ResponseWriter, r *http.Request) {
 	resp := struct {
 		Items []Item `json:"items"`
 	}{Items: items}
-
-	err = json.NewEncoder(w).Encode(resp)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // storeImage stores an image and returns the file path and an error if any.
 // this method calculates the hash sum of the image as a file name to avoid the duplication of a same file
 // and stores it in the image directory.
-func (s *Handlers) storeImage(image []byte) (filePath string, err error) {
+func (s *Handlers) storeImage(ctx context.Context, image []byte) (filePath string, err error) {
+	ctx, span := startSpan(ctx, "storeImage", trace.WithAttributes(attribute.Int("image.size", len(image))))
+	defer func() { endSpan(span, err) }()
+
 	// STEP 4-4: add an implementation to store an image
 
 	// TODO:
 	// - calc hash sum
+	_, hashSpan := startSpan(ctx, "storeImage.hash")
 	hash := sha256.Sum256(image)
-	fileName := fmt.Sprintf("%x.jpg", hash)
+	hashSpan.End()
+	// the file is named by the sniffed type, since the uploaded file name may not tell it
+	ext, ok := imageExtension(image)
+	if !ok {
+		return "", errors.New("unsupported image type")
+	}
+	fileName := fmt.Sprintf("%x%s", hash, ext)
 
 	// - build image file path
 	filePath = filepath.Join(s.imgDirPath, fileName)
@@ -230,11 +801,14 @@ func (s *Handlers) storeImage(image []byte) (filePath string, err error) {
 	}
 
 	// - store image
-	slog.Info("Saving new image", "filename", fileName)
+	slog.InfoContext(ctx, "Saving new image", "filename", fileName)
+	_, writeSpan := startSpan(ctx, "storeImage.write")
 	err = os.WriteFile(filePath, image, 0644)
+	endSpan(writeSpan, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to save image: %w", err)
 	}
+	s.metrics.addImageBytes(imageBytesStored, int64(len(image)))
 
 	// - return the image file path
 
@@ -264,26 +838,28 @@ func parseGetImageRequest(r *http.Request) (*GetImageRequest, error) {
 func (s *Handlers) GetImage(w http.ResponseWriter, r *http.Request) {
 	req, err := parseGetImageRequest(r)
 	if err != nil {
-		slog.Warn("failed to parse get image request: ", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		slog.WarnContext(r.Context(), "failed to parse get image request: ", "error", err)
+		writeError(w, r, badRequest(err))
 		return
 	}
 
 	imgPath, err := s.buildImagePath(req.FileName)
 	if err != nil {
 		if !errors.Is(err, errImageNotFound) {
-			slog.Warn("failed to build image path: ", "error", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			slog.WarnContext(r.Context(), "failed to build image path: ", "error", err)
+			writeError(w, r, badRequest(err))
 			return
 		}
 
 		// when the image is not found, it returns the default image without an error.
-		slog.Debug("image not found", "filename", imgPath)
+		slog.DebugContext(r.Context(), "image not found", "filename", imgPath)
 		imgPath = filepath.Join(s.imgDirPath, "default.jpg")
 	}
 
-	slog.Info("returned image", "path", imgPath)
-	http.ServeFile(w, r, imgPath)
+	slog.InfoContext(r.Context(), "returned image", "path", imgPath)
+	cw := &byteCountingResponseWriter{ResponseWriter: w}
+	http.ServeFile(cw, r, imgPath)
+	s.metrics.addImageBytes(imageBytesServed, cw.n)
 }
 
 // buildImagePath builds the image path and validates it.
@@ -297,8 +873,8 @@ func (s *Handlers) buildImagePath(imageFileName string) (string, error) {
 	}
 
 	// validate the image suffix
-	if !strings.HasSuffix(imgPath, ".jpg") && !strings.HasSuffix(imgPath, ".jpeg") {
-		return "", fmt.Errorf("image path does not end with .jpg or .jpeg: %s", imgPath)
+	if !slices.Contains(imageExtensions, filepath.Ext(imgPath)) {
+		return "", fmt.Errorf("image path does not end with %s: %s", joinChoices(imageExtensions), imgPath)
 	}
 
 	// check if the image exists
@@ -320,25 +896,52 @@ func parseGetItemRequest(r *http.Request) (int, error) {
 	return itemID, nil
 }
 
+type GetItemResponse struct {
+	*Item
+	SellerRating RatingSummary `json:"seller_rating"`
+}
+
 // GetItem is a handler to return an item information for GET /images/{item_id}.(for STEP 4-5)
 func (s *Handlers) GetItem(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	itemID, err := parseGetItemRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	// anonymous users can see the item unless it is a draft
+	userID, err := parseUserID(r)
+	if err != nil && !errors.Is(err, errUserIDRequired) {
+		writeError(w, r, badRequest(err))
 		return
 	}
 
 	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+
+	sellerRating, err := s.ratingRepo.GetSellerSummary(ctx, item.SellerID)
 	if err != nil {
-		http.Error(w, "item not found", http.StatusNotFound)
+		writeError(w, r, fmt.Errorf("failed to get seller ratings: %w", err))
 		return
 	}
 
-	resp, err := json.Marshal(item)
+	// the sellers checking their own items are not counted
+	if item.SellerID != userID {
+		s.viewRecorder.Record(item.ID, userID, visitorKey(r, userID), time.Now().UTC())
+	}
+
+	resp, err := json.Marshal(GetItemResponse{Item: item, SellerRating: *sellerRating})
 	if err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("failed to encode response: %w", err))
 		return
 	}
 
@@ -347,18 +950,51 @@ func (s *Handlers) GetItem(w http.ResponseWriter, r *http.Request) {
 	w.Write(resp)
 }
 
+// parseSearchRequest parses and validates the keyword and filters to search items with.
+// Saved searches accept the same parameters.
+func parseSearchRequest(r *http.Request) (*SearchQuery, error) {
+	query := &SearchQuery{
+		Keyword:  strings.TrimSpace(r.FormValue("keyword")),
+		Category: r.FormValue("category"),
+	}
+
+	// validate the request
+	var v validator
+	v.required("keyword", query.Keyword)
+
+	if s := r.FormValue("min_price"); s != "" {
+		query.MinPrice = v.intRange("min_price", s, 0, noLimit)
+	}
+	if s := r.FormValue("max_price"); s != "" {
+		query.MaxPrice = v.intRange("max_price", s, 0, noLimit)
+	}
+	if v.valid("min_price") && v.valid("max_price") {
+		v.check(query.MaxPrice == 0 || query.MinPrice <= query.MaxPrice, "min_price", "min_price must not be greater than max_price")
+	}
+
+	// attribute filters such as attr.brand=Apple
+	query.Attributes = parseAttributes(&v, r.Form)
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// Search is a handler to search items by keyword and filters for GET /search .
+// Attribute values are matched exactly, as they are stored normalized.
 func (s *Handlers) Search(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	keyword := r.URL.Query().Get("keyword")
-	if keyword == "" {
-		http.Error(w, "keyword is required", http.StatusBadRequest)
+	query, err := parseSearchRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
 		return
 	}
 
-	rows, err := s.itemRepo.SearchByKeyword(ctx, keyword)
+	rows, err := s.itemRepo.Search(ctx, query)
 	if err != nil {
-		http.Error(w, "failed to search items", http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("failed to search items: %w", err))
 		return
 	}
 	defer rows.Close()
@@ -374,14 +1010,14 @@ func (s *Handlers) Search(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var item itemResponse
 		if err := rows.Scan(&item.ID, &item.Name, &item.Category, &item.Image); err != nil {
-			http.Error(w, "failed to scan item", http.StatusInternalServerError)
+			writeError(w, r, fmt.Errorf("failed to scan item: %w", err))
 			return
 		}
 		responseItems = append(responseItems, item)
 	}
 
 	if err := rows.Err(); err != nil {
-		http.Error(w, "error iterating through items", http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("failed to iterate through items: %w", err))
 		return
 	}
 
@@ -1,18 +1,23 @@
 package app
 
 import (
-	"bytes"          //add in STEP6-1
+	"bufio"
+	"bytes" //add in STEP6-1
+	"context"
 	"database/sql"   //add in STEP6-4
 	"encoding/json"  //add in STEP6-2
 	"fmt"            //add in STEP6-3
 	"io"             //add in STEP6-1
 	"mime/multipart" //add in STEP6-1
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"            //add in STEP6-1
 	"path/filepath" //add in STEP6-1
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	_ "github.com/mattn/go-sqlite3" //add in STEP6-4
@@ -156,9 +161,12 @@ func TestAddItem(t *testing.T) {
 
 	type wants struct {
 		code int
+		// imageStored is whether the image has been written
+		imageStored bool
 	}
 	cases := map[string]struct {
 		args     map[string]string
+		rules    []ModerationRule
 		injector func(m *MockItemRepository)
 		wants
 	}{
@@ -175,7 +183,38 @@ func TestAddItem(t *testing.T) {
 				m.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
 			wants: wants{
-				code: http.StatusOK,
+				code:        http.StatusOK,
+				imageStored: true,
+			},
+		},
+		"ok: flagged item is held for review": {
+			args: map[string]string{
+				"name":     "used iPhone 16e",
+				"category": "phone",
+				"image":    "dummy.png",
+			},
+			rules: []ModerationRule{{Word: "iphone", Reason: "prohibited"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetCategoryID(gomock.Any(), "phone").Return(1, nil).Times(1)
+				m.EXPECT().InsertQuarantined(gomock.Any(), gomock.Any(), []string{"prohibited"}).Return(&ModerationReview{}, nil).Times(1)
+			},
+			wants: wants{
+				code:        http.StatusAccepted,
+				imageStored: true,
+			},
+		},
+		"ng: invalid attributes leave no image": {
+			args: map[string]string{
+				"name":                        "used iPhone 16e",
+				"category":                    "phone",
+				"image":                       "dummy.png",
+				attributeParamPrefix + "size": "XL",
+			},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetCategoryID(gomock.Any(), "phone").Return(1, nil).Times(1)
+			},
+			wants: wants{
+				code: http.StatusUnprocessableEntity,
 			},
 		},
 		"ng: failed to insert": {
@@ -203,7 +242,14 @@ func TestAddItem(t *testing.T) {
 
 			mockIR := NewMockItemRepository(ctrl)
 			tt.injector(mockIR)
-			h := &Handlers{itemRepo: mockIR}
+			mockSSR := NewMockSavedSearchRepository(ctrl)
+			mockSSR.EXPECT().ListMatching(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+			mockASR := NewMockAttributeSchemaRepository(ctrl)
+			mockASR.EXPECT().GetSchema(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+			mockMR := NewMockModerationRepository(ctrl)
+			mockMR.EXPECT().ListRules(gomock.Any(), gomock.Any()).Return(tt.rules, nil).AnyTimes()
+			dir := t.TempDir()
+			h := &Handlers{imgDirPath: dir, itemRepo: mockIR, savedSearchRepo: mockSSR, attributeSchemaRepo: mockASR, moderationRepo: mockMR}
 
 			var b bytes.Buffer
 			w := multipart.NewWriter(&b)
@@ -221,7 +267,7 @@ func TestAddItem(t *testing.T) {
 			}
 
 			//send the image for the test
-			dummyImage := []byte("dummy image data")
+			dummyImage := testJPEG
 			_, err = fileWriter.Write(dummyImage)
 			if err != nil {
 				t.Fatalf("failed to write dummy imagedata: %v", err)
@@ -238,6 +284,13 @@ func TestAddItem(t *testing.T) {
 			if tt.wants.code != rr.Code {
 				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
 			}
+			stored, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("failed to read image directory: %v", err)
+			}
+			if got := len(stored) > 0; got != tt.wants.imageStored {
+				t.Errorf("expected image stored %v, got %v", tt.wants.imageStored, stored)
+			}
 			if tt.wants.code >= 400 {
 				return
 			}
@@ -301,7 +354,7 @@ func TestAddItemE2e(t *testing.T) {
 				}{
 					Name:      "used iphone 16e",
 					Category:  "phone",
-					ImageName: "4b29735ca4495218624e7723bd28f56bb8c3938e21640cd7d8d0bf79360d5551.jpg",
+					ImageName: "66476e15fc0c230126e5772e4b923dde2701a380d23a65eaf77e113f328ff0b0.jpg",
 				},
 			},
 		},
@@ -315,7 +368,7 @@ func TestAddItemE2e(t *testing.T) {
 				m.EXPECT().GetCategoryID(gomock.Any(), "phone").Return(0, fmt.Errorf("category not found"))
 			},
 			wants: wants{
-				code: http.StatusBadRequest,
+				code: http.StatusUnprocessableEntity,
 				response: struct {
 					Name      string
 					Category  string
@@ -331,7 +384,14 @@ func TestAddItemE2e(t *testing.T) {
 
 	for name, tt := range cases {
 		t.Run(name, func(t *testing.T) {
-			h := &Handlers{itemRepo: &itemRepository{db: db}}
+			h := &Handlers{
+				itemRepo:        &itemRepository{db: db},
+				savedSearchRepo: &savedSearchRepository{db: db},
+				notifier:        NewInAppNotifier(&notificationRepository{db: db}),
+
+				attributeSchemaRepo: &attributeSchemaRepository{db: db},
+				moderationRepo:      &moderationRepository{db: db},
+			}
 
 			var body bytes.Buffer
 			writer := multipart.NewWriter(&body)
@@ -340,7 +400,7 @@ func TestAddItemE2e(t *testing.T) {
 			}
 
 			fileWriter, _ := writer.CreateFormFile("image", tt.args["image"])
-			fileWriter.Write([]byte("dummy image content"))
+			fileWriter.Write(testJPEG)
 			writer.Close()
 
 			req := httptest.NewRequest("POST", "/items", &body)
@@ -388,6 +448,12 @@ func TestAddItemE2e(t *testing.T) {
 	}
 }
 
+// testJPEG and testPNG start like a JPEG and a PNG, so that they are sniffed as such on upload.
+var (
+	testJPEG = []byte("\xff\xd8\xff\xe0dummy image data")
+	testPNG  = []byte("\x89PNG\r\n\x1a\ndummy image data")
+)
+
 func setupDB(t *testing.T) (db *sql.DB, closers []func(), e error) {
 	t.Helper()
 
@@ -410,7 +476,7 @@ func setupDB(t *testing.T) (db *sql.DB, closers []func(), e error) {
 	})
 
 	// set up tables
-	db, err = sql.Open("sqlite3", f.Name())
+	db, err = sql.Open(sqliteDriverName, f.Name())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -432,3 +498,174 @@ func setupDB(t *testing.T) (db *sql.DB, closers []func(), e error) {
 
 	return db, closers, nil
 }
+
+func TestGetImage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{"item.jpg": testJPEG, "item.png": testPNG, "item.gif": []byte("GIF89a")} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatalf("failed to write image: %v", err)
+		}
+	}
+	h := &Handlers{imgDirPath: dir}
+
+	type wants struct {
+		code        int
+		contentType string
+	}
+	cases := map[string]struct {
+		fileName string
+		wants
+	}{
+		"ok: jpeg": {
+			fileName: "item.jpg",
+			wants:    wants{code: http.StatusOK, contentType: "image/jpeg"},
+		},
+		"ok: png": {
+			fileName: "item.png",
+			wants:    wants{code: http.StatusOK, contentType: "image/png"},
+		},
+		"ng: unsupported extension": {
+			fileName: "item.gif",
+			wants:    wants{code: http.StatusBadRequest},
+		},
+		"ng: directory traversal": {
+			fileName: "../item.jpg",
+			wants:    wants{code: http.StatusBadRequest},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "/images/"+tt.fileName, nil)
+			req.SetPathValue("filename", tt.fileName)
+			rr := httptest.NewRecorder()
+			h.GetImage(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Fatalf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+			if tt.wants.contentType != "" && rr.Header().Get("Content-Type") != tt.wants.contentType {
+				t.Errorf("expected content type %s, got %s", tt.wants.contentType, rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// readListener reports when the server starts reading a connection it accepted,
+// by which time the server tracks the connection as in flight.
+type readListener struct {
+	net.Listener
+	reading chan struct{}
+}
+
+func (l *readListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &readConn{Conn: conn, reading: l.reading}, nil
+}
+
+type readConn struct {
+	net.Conn
+	reading chan struct{}
+	once    sync.Once
+}
+
+func (c *readConn) Read(b []byte) (int, error) {
+	c.once.Do(func() { c.reading <- struct{}{} })
+	return c.Conn.Read(b)
+}
+
+func TestServerServe(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	reading := make(chan struct{}, 1)
+	addr := ln.Addr().String()
+
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.ImageDirPath = dir
+	cfg.DBPath = filepath.Join(dir, "mercari.sqlite3")
+	cfg.ShutdownTimeout = 5 * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	shuttingDown := make(chan struct{})
+	srv := Server{Config: cfg, onShutdown: func() { close(shuttingDown) }}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ctx, &readListener{Listener: ln, reading: reading})
+	}()
+
+	// start an upload, and finish it only after the shutdown has begun
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	_ = w.WriteField("name", "jacket")
+	_ = w.WriteField("category", "fashion")
+	fw, err := w.CreateFormFile("image", "jacket.jpg")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	_, _ = fw.Write(testJPEG)
+	w.Close()
+
+	// send the headers and a part of the body over a raw connection to control the timing
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	data := body.Bytes()
+	header := fmt.Sprintf("POST /items HTTP/1.1\r\nHost: 127.0.0.1\r\nContent-Type: %s\r\nContent-Length: %d\r\n\r\n", w.FormDataContentType(), len(data))
+	if _, err := conn.Write(append([]byte(header), data[:10]...)); err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+
+	// the request is in flight once the server reads it
+	<-reading
+	cancel()
+	<-shuttingDown
+	select {
+	case err := <-done:
+		t.Fatalf("server stopped before the upload finished: %v", err)
+	default:
+	}
+	if _, err := conn.Write(data[10:]); err != nil {
+		t.Fatalf("failed to write request body: %v", err)
+	}
+
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("the in-flight request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a graceful shutdown, got %v", err)
+		}
+	case <-time.After(cfg.ShutdownTimeout):
+		t.Fatal("server did not stop")
+	}
+
+	// new connections are refused
+	if res, err := http.Get("http://" + addr + "/"); err == nil {
+		res.Body.Close()
+		t.Error("expected the server to refuse connections after the shutdown")
+	}
+}
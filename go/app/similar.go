@@ -0,0 +1,69 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// similarCandidateLimit is the number of best ranked items loaded to fill the pages of similar items.
+const similarCandidateLimit = 200
+
+// GetSimilarItems is a handler to return the on-sale items similar to an item for GET /items/{item_id}/similar .
+func (s *Handlers) GetSimilarItems(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	// anonymous users can see the similar items of any item they can see
+	userID, err := parseUserID(r)
+	if err != nil && !errors.Is(err, errUserIDRequired) {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+
+	ids := s.similarIndex.Similar(item, time.Now().UTC(), similarCandidateLimit)
+	candidates, err := s.itemRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get similar items: %w", err))
+		return
+	}
+
+	// an item may have been sold since it was ranked, so the items are filtered in the order of the ranking
+	byID := make(map[int]Item, len(candidates))
+	for _, c := range candidates {
+		byID[c.ID] = c
+	}
+	items := []Item{}
+	for _, id := range ids {
+		if c, ok := byID[id]; ok && c.Status == ItemStatusOnSale {
+			items = append(items, c)
+		}
+	}
+	items = items[min(offset, len(items)):min(offset+limit, len(items))]
+
+	writeJSON(w, http.StatusOK, struct {
+		Items  []Item `json:"items"`
+		Limit  int    `json:"limit"`
+		Offset int    `json:"offset"`
+	}{Items: items, Limit: limit, Offset: offset})
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// The weights of the signals ranking similar items. They add up to 1.
+const (
+	similarNameWeight     = 0.6
+	similarCategoryWeight = 0.3
+	similarRecencyWeight  = 0.1
+)
+
+// similarRecencyHalfLife is the age at which the recency signal of an item is halved.
+const similarRecencyHalfLife = 14 * 24 * time.Hour
+
+// nameTokens splits a name into normalized words: full-width and half-width forms are unified,
+// letters are lowercased and the words are split at spaces and symbols.
+func nameTokens(name string) []string {
+	words := strings.FieldsFunc(strings.ToLower(norm.NFKC.String(name)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	var tokens []string
+	for _, w := range words {
+		if !slices.Contains(tokens, w) {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// similarityDocument is an item as stored in the similarity index.
+type similarityDocument struct {
+	categoryID int
+	tokens     []string
+	createdAt  time.Time
+}
+
+// SimilarityIndex keeps the name tokens of the items on sale in memory to rank similar items
+// by category, TF-IDF of the names and recency without scanning the database.
+// It is built at startup and refreshed by the repositories changing the items and their statuses,
+// so that the sold items neither take the places of the candidates nor weigh on the IDF.
+type SimilarityIndex struct {
+	mu   sync.RWMutex
+	docs map[int]similarityDocument
+	// df is the number of documents containing each token.
+	df map[string]int
+}
+
+// NewSimilarityIndex creates an empty SimilarityIndex.
+func NewSimilarityIndex() *SimilarityIndex {
+	return &SimilarityIndex{docs: map[int]similarityDocument{}, df: map[string]int{}}
+}
+
+// Add adds the item to the index, replacing it if it is already indexed. A nil index does nothing.
+func (x *SimilarityIndex) Add(item *Item) {
+	if x == nil {
+		return
+	}
+	doc := similarityDocument{categoryID: item.CategoryID, tokens: nameTokens(item.Name), createdAt: item.CreatedAt}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(item.ID)
+	for _, t := range doc.tokens {
+		x.df[t]++
+	}
+	x.docs[item.ID] = doc
+}
+
+// Remove removes the item from the index, if it is indexed. A nil index does nothing.
+func (x *SimilarityIndex) Remove(itemID int) {
+	if x == nil {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(itemID)
+}
+
+// remove removes the item and its tokens. The caller must hold the lock.
+func (x *SimilarityIndex) remove(itemID int) {
+	old, ok := x.docs[itemID]
+	if !ok {
+		return
+	}
+	for _, t := range old.tokens {
+		x.df[t]--
+		if x.df[t] == 0 {
+			delete(x.df, t)
+		}
+	}
+	delete(x.docs, itemID)
+}
+
+// Sync adds the item to the index if it is on sale and removes it otherwise,
+// as when its status has changed. A nil index does nothing.
+func (x *SimilarityIndex) Sync(item *Item) {
+	if item.Status == ItemStatusOnSale {
+		x.Add(item)
+	} else {
+		x.Remove(item.ID)
+	}
+}
+
+// idf returns the smoothed inverse document frequency of the token. The caller must hold the lock.
+func (x *SimilarityIndex) idf(token string) float64 {
+	return math.Log(float64(len(x.docs)+1)/float64(x.df[token]+1)) + 1
+}
+
+// Similar returns the IDs of at most n other items most similar to the item, best first.
+// Items sharing neither the category nor a name token are never returned.
+func (x *SimilarityIndex) Similar(item *Item, now time.Time, n int) []int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	// each token appears once in a name, so the TF-IDF weight of a token is its IDF
+	query := map[string]float64{}
+	var queryNorm float64
+	for _, t := range nameTokens(item.Name) {
+		w := x.idf(t)
+		query[t] = w
+		queryNorm += w * w
+	}
+	queryNorm = math.Sqrt(queryNorm)
+
+	type scored struct {
+		id    int
+		score float64
+	}
+	var ranked []scored
+	for id, doc := range x.docs {
+		if id == item.ID {
+			continue
+		}
+
+		var dot, docNorm float64
+		for _, t := range doc.tokens {
+			w := x.idf(t)
+			docNorm += w * w
+			dot += w * query[t]
+		}
+		var nameScore float64
+		if dot > 0 {
+			nameScore = dot / (queryNorm * math.Sqrt(docNorm))
+		}
+		var categoryScore float64
+		if item.CategoryID != 0 && doc.categoryID == item.CategoryID {
+			categoryScore = 1
+		}
+		if nameScore == 0 && categoryScore == 0 {
+			continue
+		}
+		age := max(now.Sub(doc.createdAt), 0)
+		recencyScore := math.Pow(0.5, float64(age)/float64(similarRecencyHalfLife))
+
+		score := similarNameWeight*nameScore + similarCategoryWeight*categoryScore + similarRecencyWeight*recencyScore
+		ranked = append(ranked, scored{id: id, score: score})
+	}
+
+	// newer items first on ties, so that the order is stable
+	slices.SortFunc(ranked, func(a, b scored) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.id, a.id)
+	})
+
+	ids := make([]int, 0, min(n, len(ranked)))
+	for _, s := range ranked[:min(n, len(ranked))] {
+		ids = append(ids, s.id)
+	}
+	return ids
+}
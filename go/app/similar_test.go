@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSimilarityIndex(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	index := NewSimilarityIndex()
+	for _, item := range []*Item{
+		{ID: 1, Name: "Nike Air Jacket", CategoryID: 1, CreatedAt: now},
+		{ID: 2, Name: "nike jacket", CategoryID: 1, CreatedAt: now.Add(-time.Hour)},
+		{ID: 3, Name: "denim jacket", CategoryID: 1, CreatedAt: now.Add(-30 * 24 * time.Hour)},
+		{ID: 4, Name: "nike shoes", CategoryID: 2, CreatedAt: now},
+		{ID: 5, Name: "wool scarf", CategoryID: 1, CreatedAt: now},
+		{ID: 6, Name: "coffee mug", CategoryID: 3, CreatedAt: now},
+	} {
+		index.Add(item)
+	}
+
+	cases := map[string]struct {
+		item *Item
+		want []int
+	}{
+		"ok: ranked by name, category and recency": {
+			item: &Item{ID: 1, Name: "Nike Air Jacket", CategoryID: 1},
+			want: []int{2, 3, 5, 4},
+		},
+		"ok: unindexed item": {
+			item: &Item{ID: 99, Name: "ｃｏｆｆｅｅ cup", CategoryID: 4},
+			want: []int{6},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, index.Similar(tt.item, now, 10)); diff != "" {
+				t.Errorf("unexpected items (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSimilarityIndexAdd(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	index := NewSimilarityIndex()
+	index.Add(&Item{ID: 1, Name: "coffee mug", CategoryID: 1, CreatedAt: now})
+	index.Add(&Item{ID: 2, Name: "tea cup", CategoryID: 2, CreatedAt: now})
+
+	// renaming the item replaces its tokens, and ties are broken by newer IDs
+	index.Add(&Item{ID: 1, Name: "tea pot", CategoryID: 1, CreatedAt: now})
+
+	if got := index.Similar(&Item{ID: 3, Name: "coffee", CategoryID: 9}, now, 10); len(got) != 0 {
+		t.Errorf("expected no similar items, got %v", got)
+	}
+	if diff := cmp.Diff([]int{2, 1}, index.Similar(&Item{ID: 3, Name: "tea", CategoryID: 9}, now, 10)); diff != "" {
+		t.Errorf("unexpected items (-want +got):\n%s", diff)
+	}
+	if index.df["coffee"] != 0 || index.df["tea"] != 2 {
+		t.Errorf("unexpected document frequencies: %v", index.df)
+	}
+}
+
+func TestSimilarityIndexSync(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	index := NewSimilarityIndex()
+	index.Sync(&Item{ID: 1, Name: "tea pot", CategoryID: 1, Status: ItemStatusOnSale, CreatedAt: now})
+	index.Sync(&Item{ID: 2, Name: "tea cup", CategoryID: 1, Status: ItemStatusDraft, CreatedAt: now})
+	index.Sync(&Item{ID: 3, Name: "tea set", CategoryID: 1, Status: ItemStatusSoldOut, CreatedAt: now})
+	index.Sync(&Item{ID: 4, Name: "tea bowl", CategoryID: 1, Status: ItemStatusOnSale, CreatedAt: now})
+	// an item leaving the sale is removed with its tokens
+	index.Sync(&Item{ID: 1, Name: "tea pot", CategoryID: 1, Status: ItemStatusHidden, CreatedAt: now})
+
+	if diff := cmp.Diff([]int{4}, index.Similar(&Item{ID: 9, Name: "tea", CategoryID: 9}, now, 10)); diff != "" {
+		t.Errorf("unexpected items (-want +got):\n%s", diff)
+	}
+	if index.df["tea"] != 1 || index.df["pot"] != 0 || index.df["set"] != 0 {
+		t.Errorf("unexpected document frequencies: %v", index.df)
+	}
+}
+
+func TestSimilarityIndexStatuses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	ctx := context.Background()
+	index := NewSimilarityIndex()
+	itemRepo := NewItemRepository(db, index)
+	moderationRepo := NewModerationRepository(db, index)
+	indexed := func() []int {
+		return index.Similar(&Item{Name: "jacket", CategoryID: 1}, time.Now(), 10)
+	}
+
+	draft := &Item{Name: "jacket", CategoryID: 1, Image: "a.jpg", Status: ItemStatusDraft}
+	if err := itemRepo.Insert(ctx, draft); err != nil {
+		t.Fatalf("failed to insert item: %v", err)
+	}
+	if got := indexed(); len(got) != 0 {
+		t.Errorf("expected the draft not to be indexed, got %v", got)
+	}
+
+	if err := itemRepo.Publish(ctx, draft.ID); err != nil {
+		t.Fatalf("failed to publish item: %v", err)
+	}
+	if diff := cmp.Diff([]int{draft.ID}, indexed()); diff != "" {
+		t.Errorf("expected the published item to be indexed (-want +got):\n%s", diff)
+	}
+
+	if _, err := moderationRepo.Quarantine(ctx, draft.ID, []string{"prohibited"}); err != nil {
+		t.Fatalf("failed to quarantine item: %v", err)
+	}
+	if got := indexed(); len(got) != 0 {
+		t.Errorf("expected the quarantined item to be removed, got %v", got)
+	}
+}
+
+func TestSimilarityIndexSoldItems(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	ctx := context.Background()
+	index := NewSimilarityIndex()
+	itemRepo := NewItemRepository(db, index)
+	orderRepo := NewOrderRepository(db, index)
+	var items []*Item
+	for _, name := range []string{"nike air jacket", "nike air jacket", "nike air jacket", "wool jacket"} {
+		item := &Item{Name: name, CategoryID: 1, Image: "a.jpg", SellerID: 1, Price: 1000, Status: ItemStatusOnSale}
+		if err := itemRepo.Insert(ctx, item); err != nil {
+			t.Fatalf("failed to insert item: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	// the best matches of the first item are sold, which leaves the weaker match as the best candidate
+	for _, item := range items[1:3] {
+		order := &Order{ItemID: item.ID, BuyerID: 2, SellerID: 1, Price: item.Price, Status: OrderStatusPurchased, CreatedAt: time.Now().UTC()}
+		if err := orderRepo.Create(ctx, order); err != nil {
+			t.Fatalf("failed to create order: %v", err)
+		}
+	}
+
+	if diff := cmp.Diff([]int{items[3].ID}, index.Similar(items[0], time.Now(), 1)); diff != "" {
+		t.Errorf("unexpected similar items (-want +got):\n%s", diff)
+	}
+	if index.df["nike"] != 1 {
+		t.Errorf("expected the sold items to leave the document frequencies, got %v", index.df)
+	}
+}
+
+func TestGetSimilarItems(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	index := NewSimilarityIndex()
+	for _, item := range []*Item{
+		{ID: 1, Name: "nike jacket", CategoryID: 1, CreatedAt: now},
+		{ID: 2, Name: "nike jacket", CategoryID: 1, CreatedAt: now},
+		{ID: 3, Name: "nike jacket", CategoryID: 1, CreatedAt: now},
+	} {
+		index.Add(item)
+	}
+
+	type wants struct {
+		code int
+		ids  []int
+	}
+	cases := map[string]struct {
+		// injector is used to inject the expected calls to the mock
+		injector func(m *MockItemRepository)
+		wants
+	}{
+		"ok: items not on sale are skipped": {
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "nike jacket", CategoryID: 1, SellerID: 1, Status: ItemStatusOnSale}, nil)
+				m.EXPECT().ListByIDs(gomock.Any(), []int{3, 2}).Return([]Item{
+					{ID: 2, Status: ItemStatusOnSale},
+					{ID: 3, Status: ItemStatusSoldOut},
+				}, nil)
+			},
+			wants: wants{code: http.StatusOK, ids: []int{2}},
+		},
+		"ng: draft of another user": {
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 1, Status: ItemStatusDraft}, nil)
+			},
+			wants: wants{code: http.StatusNotFound},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			tt.injector(mockIR)
+			h := &Handlers{itemRepo: mockIR, similarIndex: index}
+
+			req := httptest.NewRequest("GET", "/items/1/similar", nil)
+			req.SetPathValue("item_id", "1")
+			rr := httptest.NewRecorder()
+			h.GetSimilarItems(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Fatalf("expected status code %d, got %d: %s", tt.wants.code, rr.Code, rr.Body.String())
+			}
+			if rr.Code != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Items []Item `json:"items"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var ids []int
+			for _, item := range resp.Items {
+				ids = append(ids, item.ID)
+			}
+			if diff := cmp.Diff(tt.wants.ids, ids); diff != "" {
+				t.Errorf("unexpected items (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// tracerName is the instrumentation scope of the spans of the server.
+	tracerName = "mercari-build-training/app"
+	// serviceName is the service.name of the spans unless OTEL_SERVICE_NAME is set.
+	serviceName = "mercari-build-training"
+)
+
+// The trace exporters selected by Config.TraceExporter.
+const (
+	traceExporterNone   = "none"
+	traceExporterOTLP   = "otlp"
+	traceExporterStdout = "stdout"
+)
+
+// propagator reads and writes the W3C trace context and baggage headers.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// setupTracing creates the tracer provider for the exporter and installs it globally.
+// The OTLP exporter sends the spans over HTTP to the collector set by the standard
+// OTEL_EXPORTER_OTLP_* environment variables, localhost:4318 by default, and the stdout
+// exporter writes them to w. The returned function flushes the pending spans.
+func setupTracing(ctx context.Context, exporter string, w io.Writer) (trace.TracerProvider, func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+
+	var exp sdktrace.SpanExporter
+	switch exporter {
+	case traceExporterNone:
+		tp := noop.NewTracerProvider()
+		otel.SetTracerProvider(tp)
+		return tp, func(context.Context) error { return nil }, nil
+	case traceExporterOTLP:
+		var err error
+		exp, err = otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+	case traceExporterStdout:
+		var err error
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName(serviceName), semconv.ServiceVersion(buildVersion().Version)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp, tp.Shutdown, nil
+}
+
+// startSpan starts a child of the span in ctx. The span is created by the tracer provider
+// of the parent, so nothing is recorded outside a traced request, such as in tests.
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// endSpan records the error, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
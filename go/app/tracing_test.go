@@ -0,0 +1,112 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctrl := gomock.NewController(t)
+	mockIR := NewMockItemRepository(ctrl)
+	mockIR.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errors.New("database is locked"))
+	h := &Handlers{imgDirPath: t.TempDir(), itemRepo: instrumentItemRepository(mockIR, nil)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /items/{item_id}", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := h.storeImage(r.Context(), testJPEG); err != nil {
+			t.Errorf("failed to store image: %v", err)
+		}
+		if _, err := h.itemRepo.GetByID(r.Context(), 1); err != nil {
+			http.Error(w, "failed to get item", http.StatusInternalServerError)
+		}
+	})
+
+	const traceID = "0af7651916cd43dd8448eb211c80319c"
+	req := httptest.NewRequest("POST", "/items/1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-b7ad6b7169203331-01")
+	tracingMiddleware(mux, tp).ServeHTTP(httptest.NewRecorder(), req)
+
+	type span struct {
+		Name   string
+		Parent string
+		Status codes.Code
+	}
+	var got []span
+	byID := map[string]string{}
+	for _, s := range recorder.Ended() {
+		if s.SpanContext().TraceID().String() != traceID {
+			t.Errorf("span %s is not in the incoming trace", s.Name())
+		}
+		byID[s.SpanContext().SpanID().String()] = s.Name()
+	}
+	for _, s := range recorder.Ended() {
+		parent, ok := byID[s.Parent().SpanID().String()]
+		if !ok {
+			parent = "remote:" + s.Parent().SpanID().String()
+		}
+		got = append(got, span{Name: s.Name(), Parent: parent, Status: s.Status().Code})
+	}
+	want := []span{
+		{Name: "storeImage.hash", Parent: "storeImage"},
+		{Name: "storeImage.write", Parent: "storeImage"},
+		{Name: "storeImage", Parent: "POST /items/{item_id}"},
+		{Name: "ItemRepository.GetByID", Parent: "POST /items/{item_id}", Status: codes.Error},
+		{Name: "POST /items/{item_id}", Parent: "remote:b7ad6b7169203331", Status: codes.Error},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected spans (-want +got):\n%s", diff)
+	}
+
+	server := recorder.Ended()[len(recorder.Ended())-1]
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range server.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if got := attrs["http.route"].AsString(); got != "POST /items/{item_id}" {
+		t.Errorf("expected the route attribute, got %q", got)
+	}
+	if got := attrs["http.response.status_code"].AsInt64(); got != http.StatusInternalServerError {
+		t.Errorf("expected the status code attribute, got %d", got)
+	}
+}
+
+func TestSetupTracing(t *testing.T) {
+	var out bytes.Buffer
+	tp, shutdown, err := setupTracing(context.Background(), traceExporterStdout, &out)
+	if err != nil {
+		t.Fatalf("failed to set up tracing: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {})
+	tracingMiddleware(mux, tp).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+	// the spans are exported in batches, so they are written on shutdown
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down tracing: %v", err)
+	}
+	if !strings.Contains(out.String(), `"Name":"GET /healthz"`) {
+		t.Errorf("expected the span in the output, got %q", out.String())
+	}
+
+	if _, _, err := setupTracing(context.Background(), "jaeger", &out); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// trendingInterval is how often the trending scores are recomputed.
+	trendingInterval = time.Minute
+	// trendingWindow is how far back the events count toward the trending scores.
+	trendingWindow = 72 * time.Hour
+	// trendingHalfLife is the age at which an event counts half.
+	trendingHalfLife = 6 * time.Hour
+	// trendingCacheSize is the number of best scored items kept in memory.
+	trendingCacheSize = 500
+	// eventRetention is how long the item events are kept.
+	eventRetention = 30 * 24 * time.Hour
+	// searchClickDedupWindow is how long the search clicks of an item by the same visitor count once.
+	searchClickDedupWindow = 30 * time.Minute
+)
+
+// trendingWeights are how much each kind of event counts toward the trending score.
+var trendingWeights = map[ItemEventType]float64{
+	ItemEventView:        1,
+	ItemEventSearchClick: 2,
+	ItemEventLike:        3,
+}
+
+// TrendingScore is the time-decayed popularity of an item.
+type TrendingScore struct {
+	ItemID int     `json:"item_id"`
+	Score  float64 `json:"score"`
+}
+
+// TrendingCache holds the latest trending scores computed by the background job.
+type TrendingCache struct {
+	mu         sync.RWMutex
+	scores     []TrendingScore
+	computedAt time.Time
+}
+
+// Set replaces the cached scores.
+func (c *TrendingCache) Set(scores []TrendingScore, computedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scores, c.computedAt = scores, computedAt
+}
+
+// Get returns the cached scores, best first, and when they were computed.
+// The slice must not be modified.
+func (c *TrendingCache) Get() ([]TrendingScore, time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scores, c.computedAt
+}
+
+// trendingScores weighs the decayed totals of the events by their types,
+// and returns at most n items, best first.
+func trendingScores(totals []ItemEventTotal, n int) []TrendingScore {
+	byItem := map[int]float64{}
+	for _, t := range totals {
+		byItem[t.ItemID] += trendingWeights[t.Type] * t.Total
+	}
+
+	scores := make([]TrendingScore, 0, len(byItem))
+	for id, score := range byItem {
+		if score > 0 {
+			scores = append(scores, TrendingScore{ItemID: id, Score: score})
+		}
+	}
+	// newer items first on ties, so that the order is stable
+	slices.SortFunc(scores, func(a, b TrendingScore) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ItemID, a.ItemID)
+	})
+	return scores[:min(n, len(scores))]
+}
+
+// refreshTrending recomputes the trending scores and prunes the expired events.
+// It is run at startup and periodically by the scheduler started in Server.Run.
+func (s *Handlers) refreshTrending(ctx context.Context) {
+	now := time.Now().UTC()
+	totals, err := s.eventRepo.SumDecayed(ctx, now.Add(-trendingWindow), now, trendingHalfLife)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to sum item events: ", "error", err)
+		return
+	}
+	s.trending.Set(trendingScores(totals, trendingCacheSize), now)
+
+	if _, err := s.eventRepo.DeleteBefore(ctx, now.Add(-eventRetention)); err != nil {
+		slog.ErrorContext(ctx, "failed to prune item events: ", "error", err)
+	}
+}
+
+// recordEvent records an interaction with an item. Events only feed the rankings,
+// so a failure is logged and doesn't fail the request.
+func (s *Handlers) recordEvent(ctx context.Context, itemID int, typ ItemEventType, userID int) {
+	if err := s.eventRepo.Record(ctx, &ItemEvent{ItemID: itemID, Type: typ, UserID: userID}); err != nil {
+		slog.ErrorContext(ctx, "failed to record item event: ", "error", err, "type", typ)
+	}
+}
+
+// clickDeduper tells the first click on an item by a visitor within a window from the repeated ones,
+// as the ViewRecorder does for the views.
+type clickDeduper struct {
+	window time.Duration
+
+	mu     sync.Mutex
+	seen   map[viewKey]time.Time
+	pruned time.Time
+}
+
+// newClickDeduper creates a clickDeduper counting the clicks of a visitor on an item once per window.
+func newClickDeduper(window time.Duration) *clickDeduper {
+	return &clickDeduper{window: window, seen: map[viewKey]time.Time{}}
+}
+
+// first reports whether the click is the first on the item by the visitor within the window,
+// and remembers it if so. The visitors whose window has passed are forgotten once per window.
+func (d *clickDeduper) first(itemID int, visitor string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.pruned) >= d.window {
+		for key, last := range d.seen {
+			if now.Sub(last) >= d.window {
+				delete(d.seen, key)
+			}
+		}
+		d.pruned = now
+	}
+
+	key := viewKey{itemID: itemID, visitor: visitor}
+	if last, ok := d.seen[key]; ok && now.Sub(last) < d.window {
+		return false
+	}
+	d.seen[key] = now
+	return true
+}
+
+// RecordSearchClick is a handler to record an item opened from the search results for POST /items/{item_id}/search-clicks .
+// Repeated clicks on an item by the same visitor within searchClickDedupWindow count once.
+func (s *Handlers) RecordSearchClick(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+	userID, err := parseUserID(r)
+	if err != nil && !errors.Is(err, errUserIDRequired) {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.Status.listed() {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+
+	if s.searchClicks.first(itemID, visitorKey(r, userID), time.Now().UTC()) {
+		s.recordEvent(ctx, itemID, ItemEventSearchClick, userID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTrendingItems is a handler to return the on-sale items with the highest trending scores for GET /items/trending .
+func (s *Handlers) GetTrendingItems(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	scores, computedAt := s.trending.Get()
+	ids := make([]int, len(scores))
+	for k, sc := range scores {
+		ids[k] = sc.ItemID
+	}
+	candidates, err := s.itemRepo.ListByIDs(r.Context(), ids)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get trending items: %w", err))
+		return
+	}
+
+	type trendingItem struct {
+		Item
+		Score float64 `json:"score"`
+	}
+	// the scores are computed periodically, so the items sold or hidden since then are filtered here
+	byID := make(map[int]Item, len(candidates))
+	for _, c := range candidates {
+		byID[c.ID] = c
+	}
+	items := []trendingItem{}
+	for _, sc := range scores {
+		if c, ok := byID[sc.ItemID]; ok && c.Status == ItemStatusOnSale {
+			items = append(items, trendingItem{Item: c, Score: sc.Score})
+		}
+	}
+	items = items[min(offset, len(items)):min(offset+limit, len(items))]
+
+	writeJSON(w, http.StatusOK, struct {
+		Items      []trendingItem `json:"items"`
+		ComputedAt time.Time      `json:"computed_at"`
+		Limit      int            `json:"limit"`
+		Offset     int            `json:"offset"`
+	}{Items: items, ComputedAt: computedAt, Limit: limit, Offset: offset})
+}
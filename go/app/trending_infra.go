@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ItemEventType is the kind of interaction with an item.
+type ItemEventType string
+
+const (
+	ItemEventView ItemEventType = "view"
+	// ItemEventLike is an item added to a watch list.
+	ItemEventLike ItemEventType = "like"
+	// ItemEventSearchClick is an item opened from the search results.
+	ItemEventSearchClick ItemEventType = "search_click"
+)
+
+// ItemEvent is an interaction of a user with an item.
+type ItemEvent struct {
+	ID     int           `db:"id" json:"id"`
+	ItemID int           `db:"item_id" json:"item_id"`
+	Type   ItemEventType `db:"type" json:"type"`
+	// UserID is 0 for anonymous users.
+	UserID int `db:"user_id" json:"user_id,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ItemEventTotal is the time-decayed number of the events of a type on an item.
+type ItemEventTotal struct {
+	ItemID int
+	Type   ItemEventType
+	Total  float64
+}
+
+// EventRepository is an interface to manage the item events.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type EventRepository interface {
+	Record(ctx context.Context, event *ItemEvent) error
+	// SumDecayed returns the totals of the events created at or after since by item and type,
+	// each event counting half as much every halfLife before now.
+	SumDecayed(ctx context.Context, since, now time.Time, halfLife time.Duration) ([]ItemEventTotal, error)
+	// DeleteBefore deletes the events created before the time and returns how many were deleted.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// eventRepository is an implementation of EventRepository
+type eventRepository struct {
+	db *sql.DB
+}
+
+// NewEventRepository creates a new eventRepository.
+func NewEventRepository(database *sql.DB) EventRepository {
+	return &eventRepository{db: database}
+}
+
+func (e *eventRepository) Record(ctx context.Context, event *ItemEvent) error {
+	event.CreatedAt = time.Now().UTC()
+	res, err := e.db.ExecContext(ctx, "INSERT INTO item_events (item_id, type, user_id, created_at) VALUES (?, ?, ?, ?)",
+		event.ItemID, event.Type, event.UserID, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert item event: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get item event id: %w", err)
+	}
+	event.ID = int(id)
+	return nil
+}
+
+func (e *eventRepository) SumDecayed(ctx context.Context, since, now time.Time, halfLife time.Duration) ([]ItemEventTotal, error) {
+	// julianday counts in days, so the half-life is converted to days
+	rows, err := e.db.QueryContext(ctx, `
+		SELECT item_id, type, SUM(pow(0.5, max(julianday(?) - julianday(created_at), 0) / ?))
+		FROM item_events
+		WHERE created_at >= ?
+		GROUP BY item_id, type
+		ORDER BY item_id, type`, now, halfLife.Hours()/24, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum item events: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []ItemEventTotal{}
+	for rows.Next() {
+		var t ItemEventTotal
+		if err := rows.Scan(&t.ItemID, &t.Type, &t.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan item event total: %w", err)
+		}
+		totals = append(totals, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate item event totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+func (e *eventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := e.db.ExecContext(ctx, "DELETE FROM item_events WHERE created_at < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete item events: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete item events: %w", err)
+	}
+	return n, nil
+}
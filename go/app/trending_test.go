@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTrendingScores(t *testing.T) {
+	t.Parallel()
+
+	totals := []ItemEventTotal{
+		{ItemID: 1, Type: ItemEventView, Total: 1.5},
+		{ItemID: 1, Type: ItemEventLike, Total: 0.25},
+		{ItemID: 2, Type: ItemEventLike, Total: 0.5},
+		{ItemID: 3, Type: ItemEventSearchClick, Total: 1},
+		{ItemID: 4, Type: ItemEventView, Total: 0.125},
+	}
+
+	want := []TrendingScore{
+		{ItemID: 1, Score: 2.25},
+		{ItemID: 3, Score: 2},
+		{ItemID: 2, Score: 1.5},
+	}
+	if diff := cmp.Diff(want, trendingScores(totals, 3)); diff != "" {
+		t.Errorf("unexpected scores (-want +got):\n%s", diff)
+	}
+}
+
+func TestRefreshTrending(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockER := NewMockEventRepository(ctrl)
+	mockER.EXPECT().SumDecayed(gomock.Any(), gomock.Any(), gomock.Any(), trendingHalfLife).
+		DoAndReturn(func(_ context.Context, since, now time.Time, _ time.Duration) ([]ItemEventTotal, error) {
+			if got := now.Sub(since); got != trendingWindow {
+				t.Errorf("expected the events of the last %v, got %v", trendingWindow, got)
+			}
+			return []ItemEventTotal{{ItemID: 5, Type: ItemEventLike, Total: 1}}, nil
+		})
+	mockER.EXPECT().DeleteBefore(gomock.Any(), gomock.Any()).Return(int64(0), nil)
+	h := &Handlers{eventRepo: mockER, trending: &TrendingCache{}}
+
+	h.refreshTrending(context.Background())
+
+	scores, computedAt := h.trending.Get()
+	if diff := cmp.Diff([]TrendingScore{{ItemID: 5, Score: 3}}, scores); diff != "" {
+		t.Errorf("unexpected scores (-want +got):\n%s", diff)
+	}
+	if computedAt.IsZero() {
+		t.Error("expected the computation time to be set")
+	}
+}
+
+func TestGetTrendingItems(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockIR := NewMockItemRepository(ctrl)
+	mockIR.EXPECT().ListByIDs(gomock.Any(), []int{3, 1, 2}).Return([]Item{
+		{ID: 1, Name: "jacket", Status: ItemStatusOnSale},
+		{ID: 2, Name: "shoes", Status: ItemStatusOnSale},
+		// sold since the scores were computed
+		{ID: 3, Name: "bag", Status: ItemStatusSoldOut},
+	}, nil)
+	cache := &TrendingCache{}
+	cache.Set([]TrendingScore{{ItemID: 3, Score: 9}, {ItemID: 1, Score: 5}, {ItemID: 2, Score: 1}}, time.Now())
+	h := &Handlers{itemRepo: mockIR, trending: cache}
+
+	req := httptest.NewRequest("GET", "/items/trending?limit=1", nil)
+	rr := httptest.NewRecorder()
+	h.GetTrendingItems(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Items []struct {
+			ID    int     `json:"id"`
+			Score float64 `json:"score"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].ID != 1 || resp.Items[0].Score != 5 {
+		t.Errorf("unexpected items: %+v", resp.Items)
+	}
+}
+
+func TestRecordSearchClick(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockIR := NewMockItemRepository(ctrl)
+	mockER := NewMockEventRepository(ctrl)
+	mockIR.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 2, Status: ItemStatusOnSale}, nil).Times(3)
+	// the repeated click of the first visitor is not recorded
+	mockER.EXPECT().Record(gomock.Any(), &ItemEvent{ItemID: 1, Type: ItemEventSearchClick}).Return(nil).Times(2)
+	h := &Handlers{itemRepo: mockIR, eventRepo: mockER, searchClicks: newClickDeduper(searchClickDedupWindow)}
+
+	for _, addr := range []string{"192.0.2.1:1234", "192.0.2.1:5678", "192.0.2.2:1234"} {
+		req := httptest.NewRequest("POST", "/items/1/search-clicks", nil)
+		req.SetPathValue("item_id", "1")
+		req.RemoteAddr = addr
+		rr := httptest.NewRecorder()
+		h.RecordSearchClick(rr, req)
+
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("expected status code %d, got %d", http.StatusNoContent, rr.Code)
+		}
+	}
+}
+
+func TestClickDeduper(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newClickDeduper(time.Minute)
+	if !d.first(1, "addr:192.0.2.1", now) {
+		t.Error("expected the first click to count")
+	}
+	if d.first(1, "addr:192.0.2.1", now.Add(30*time.Second)) {
+		t.Error("expected the repeated click to be ignored")
+	}
+	if !d.first(2, "addr:192.0.2.1", now.Add(30*time.Second)) {
+		t.Error("expected a click on another item to count")
+	}
+	if !d.first(1, "addr:192.0.2.1", now.Add(2*time.Minute)) {
+		t.Error("expected a click after the window to count")
+	}
+	if len(d.seen) != 1 {
+		t.Errorf("expected the visitors whose window has passed to be forgotten, got %v", d.seen)
+	}
+}
+
+func TestEventRepositorySumDecayed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	now := time.Now().UTC()
+	for _, ev := range []ItemEvent{
+		{ItemID: 1, Type: ItemEventView, CreatedAt: now},
+		{ItemID: 1, Type: ItemEventView, CreatedAt: now.Add(-trendingHalfLife)},
+		{ItemID: 1, Type: ItemEventLike, CreatedAt: now.Add(-2 * trendingHalfLife)},
+		{ItemID: 2, Type: ItemEventView, CreatedAt: now.Add(-3 * trendingHalfLife)},
+		// out of the window
+		{ItemID: 3, Type: ItemEventView, CreatedAt: now.Add(-trendingWindow - time.Hour)},
+	} {
+		_, err := db.Exec("INSERT INTO item_events (item_id, type, user_id, created_at) VALUES (?, ?, 0, ?)", ev.ItemID, ev.Type, ev.CreatedAt)
+		if err != nil {
+			t.Fatalf("failed to insert item event: %v", err)
+		}
+	}
+
+	got, err := NewEventRepository(db).SumDecayed(context.Background(), now.Add(-trendingWindow), now, trendingHalfLife)
+	if err != nil {
+		t.Fatalf("failed to sum item events: %v", err)
+	}
+	want := []ItemEventTotal{
+		{ItemID: 1, Type: ItemEventLike, Total: 0.25},
+		{ItemID: 1, Type: ItemEventView, Total: 1.5},
+		{ItemID: 2, Type: ItemEventView, Total: 0.125},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(0, 1e-6)); diff != "" {
+		t.Errorf("unexpected totals (-want +got):\n%s", diff)
+	}
+}
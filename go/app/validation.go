@@ -0,0 +1,161 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxImageSize is the maximum size of an uploaded image in bytes.
+	maxImageSize = 5 << 20
+	// maxItemNameLength is the maximum length of the name of an item in characters.
+	maxItemNameLength = 100
+	// noLimit is passed as the maximum of intRange for the numbers without a maximum.
+	noLimit = -1
+)
+
+// imageExtensions are the file extensions of the images accepted on upload.
+var imageExtensions = []string{".jpg", ".jpeg", ".png"}
+
+// imageTypes are the content types of the images accepted on upload, with the extensions they are stored with.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// imageExtension sniffs the type of the image from its first 512 bytes and returns the extension
+// to store it with, or false if it is not an accepted image whatever its file name says.
+func imageExtension(data []byte) (string, bool) {
+	ext, ok := imageTypes[http.DetectContentType(data[:min(len(data), 512)])]
+	return ext, ok
+}
+
+// FieldError is the error of a field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError has the errors of every invalid field of a request.
+// It is written as 422 with the fields as the details.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// newValidationError creates a *ValidationError of a single field, for the checks done after parsing the request.
+func newValidationError(field, format string, args ...any) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: fmt.Sprintf(format, args...)}}}
+}
+
+// validator collects the errors of the fields of a request, so that the client is told
+// about every invalid field at once. The checks return whether the field is valid,
+// and the fields already invalid are not checked again.
+type validator struct {
+	fields []FieldError
+}
+
+// addError records an error of the field. Only the first error of a field is kept.
+func (v *validator) addError(field, format string, args ...any) {
+	if !v.valid(field) {
+		return
+	}
+	v.fields = append(v.fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// check records the error of the field unless ok.
+func (v *validator) check(ok bool, field, format string, args ...any) bool {
+	if !ok {
+		v.addError(field, format, args...)
+	}
+	return ok && v.valid(field)
+}
+
+// valid reports whether the field has no error.
+func (v *validator) valid(field string) bool {
+	return !slices.ContainsFunc(v.fields, func(f FieldError) bool { return f.Field == field })
+}
+
+// err returns a *ValidationError with the errors, or nil if every field is valid.
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+// required checks that the value is not empty.
+func (v *validator) required(field, value string) bool {
+	return v.check(value != "", field, "%s is required", field)
+}
+
+// maxLength checks that the value has at most max characters.
+func (v *validator) maxLength(field, value string, max int) bool {
+	return v.check(utf8.RuneCountInString(value) <= max, field, "%s must be at most %d characters", field, max)
+}
+
+// oneOf checks that the value is one of the allowed values.
+func (v *validator) oneOf(field, value string, allowed ...string) bool {
+	return v.check(slices.Contains(allowed, value), field, "%s must be %s", field, joinChoices(allowed))
+}
+
+// intRange parses the value as an integer between min and max, or at least min if max is noLimit.
+func (v *validator) intRange(field, value string, min, max int) int {
+	n, err := strconv.Atoi(value)
+	ok := err == nil && n >= min && (max == noLimit || n <= max)
+	switch {
+	case max != noLimit:
+		v.check(ok, field, "%s must be between %d and %d", field, min, max)
+	case min == 0:
+		v.check(ok, field, "%s must be a non-negative integer", field)
+	case min == 1:
+		v.check(ok, field, "%s must be a positive integer", field)
+	default:
+		v.check(ok, field, "%s must be an integer of at least %d", field, min)
+	}
+	if !ok {
+		return 0
+	}
+	return n
+}
+
+// image checks the size, the file name and the content of an uploaded image, and reads it.
+func (v *validator) image(field string, file multipart.File, header *multipart.FileHeader) []byte {
+	if !v.check(header.Size <= maxImageSize, field, "%s must be at most %d MB", field, maxImageSize>>20) {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	if !v.check(slices.Contains(imageExtensions, ext), field, "%s must be %s", field, joinChoices(imageExtensions)) {
+		return nil
+	}
+	data, err := io.ReadAll(file)
+	if !v.check(err == nil, field, "%s cannot be read", field) {
+		return nil
+	}
+	if _, ok := imageExtension(data); !v.check(ok, field, "%s must be a JPEG or PNG image", field) {
+		return nil
+	}
+	return data
+}
+
+// joinChoices joins the allowed values as "a, b or c".
+func joinChoices(choices []string) string {
+	if len(choices) < 2 {
+		return strings.Join(choices, "")
+	}
+	return strings.Join(choices[:len(choices)-1], ", ") + " or " + choices[len(choices)-1]
+}
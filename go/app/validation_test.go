@@ -0,0 +1,154 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidator(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		// validate runs the checks on the validator
+		validate func(v *validator)
+		want     []FieldError
+	}{
+		"ok: valid": {
+			validate: func(v *validator) {
+				v.required("name", "jacket")
+				v.maxLength("name", "ジャケット", 5)
+				v.oneOf("rating", "good", "good", "bad")
+				v.intRange("price", "0", 0, noLimit)
+				v.intRange("limit", "100", 1, 100)
+			},
+		},
+		"ng: every invalid field": {
+			validate: func(v *validator) {
+				v.required("name", "")
+				v.maxLength("body", "ジャケット", 4)
+				v.oneOf("rating", "great", "good", "normal", "bad")
+				v.intRange("price", "-1", 0, noLimit)
+				v.intRange("limit", "abc", 1, 100)
+			},
+			want: []FieldError{
+				{Field: "name", Message: "name is required"},
+				{Field: "body", Message: "body must be at most 4 characters"},
+				{Field: "rating", Message: "rating must be good, normal or bad"},
+				{Field: "price", Message: "price must be a non-negative integer"},
+				{Field: "limit", Message: "limit must be between 1 and 100"},
+			},
+		},
+		"ng: first error of a field": {
+			validate: func(v *validator) {
+				if !v.required("name", "") {
+					v.addError("name", "name is invalid")
+				}
+			},
+			want: []FieldError{{Field: "name", Message: "name is required"}},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var v validator
+			tt.validate(&v)
+			err := v.err()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if diff := cmp.Diff(tt.want, validationErr.Fields); diff != "" {
+				t.Errorf("unexpected field errors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidatorImage(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		fileName string
+		content  []byte
+		want     []FieldError
+	}{
+		"ok: jpeg":                {fileName: "a.jpg", content: testJPEG},
+		"ok: png named as a jpeg": {fileName: "a.jpg", content: testPNG},
+		"ng: not an image": {
+			fileName: "a.jpg", content: []byte("<html>not an image</html>"),
+			want: []FieldError{{Field: "image", Message: "image must be a JPEG or PNG image"}},
+		},
+		"ng: other extension": {
+			fileName: "a.gif", content: testJPEG,
+			want: []FieldError{{Field: "image", Message: "image must be .jpg, .jpeg or .png"}},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var body bytes.Buffer
+			w := multipart.NewWriter(&body)
+			fw, err := w.CreateFormFile("image", tt.fileName)
+			if err != nil {
+				t.Fatalf("failed to create form file: %v", err)
+			}
+			_, _ = fw.Write(tt.content)
+			w.Close()
+			req := httptest.NewRequest("POST", "/items", &body)
+			req.Header.Set("Content-Type", w.FormDataContentType())
+			file, header, err := req.FormFile("image")
+			if err != nil {
+				t.Fatalf("failed to get form file: %v", err)
+			}
+			defer file.Close()
+
+			var v validator
+			data := v.image("image", file, header)
+			if diff := cmp.Diff(tt.want, v.fields); diff != "" {
+				t.Errorf("unexpected field errors (-want +got):\n%s", diff)
+			}
+			if tt.want == nil && !bytes.Equal(data, tt.content) {
+				t.Errorf("expected the image to be read, got %q", data)
+			}
+		})
+	}
+}
+
+func TestParseSearchRequestFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	form := url.Values{"min_price": {"x"}, "max_price": {"-5"}, "attr.": {"Apple"}}
+	req := httptest.NewRequest("GET", "/search?"+form.Encode(), nil)
+
+	_, err := parseSearchRequest(req)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	var fields []string
+	for _, f := range validationErr.Fields {
+		fields = append(fields, f.Field)
+	}
+	if got := strings.Join(fields, ","); got != "keyword,min_price,max_price,attr." {
+		t.Errorf("expected every invalid field, got %s", got)
+	}
+}
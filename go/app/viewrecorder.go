@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// viewDedupWindow is how long the views of an item by the same visitor count once.
+	viewDedupWindow = 30 * time.Minute
+	// viewFlushInterval is how often the pending views are written.
+	viewFlushInterval = 5 * time.Second
+	// viewBatchSize is the number of pending views written without waiting for the next interval.
+	viewBatchSize = 100
+	// viewMaxPending is the number of pending views kept while the database is failing.
+	// Further views are dropped, as the counts are only informative.
+	viewMaxPending = 10000
+)
+
+// viewKey identifies the views of an item by a visitor.
+type viewKey struct {
+	itemID  int
+	visitor string
+}
+
+// ViewRecorder counts the item views off the request path. Repeated views by the same visitor
+// within viewDedupWindow count once, and the views are written in batches by a background goroutine.
+type ViewRecorder struct {
+	repo AnalyticsRepository
+
+	mu      sync.Mutex
+	seen    map[viewKey]time.Time
+	pending []ItemEvent
+	full    chan struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewViewRecorder creates a ViewRecorder writing to the repository.
+func NewViewRecorder(repo AnalyticsRepository) *ViewRecorder {
+	return &ViewRecorder{
+		repo: repo,
+		seen: map[viewKey]time.Time{},
+		full: make(chan struct{}, 1),
+	}
+}
+
+// Record counts a view of the item by the visitor, identified by the user ID or else the address.
+// It returns false if the view is a duplicate or has been dropped.
+func (v *ViewRecorder) Record(itemID, userID int, visitor string, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := viewKey{itemID: itemID, visitor: visitor}
+	if last, ok := v.seen[key]; ok && now.Sub(last) < viewDedupWindow {
+		return false
+	}
+	if len(v.pending) >= viewMaxPending {
+		return false
+	}
+	v.seen[key] = now
+	v.pending = append(v.pending, ItemEvent{ItemID: itemID, Type: ItemEventView, UserID: userID, CreatedAt: now})
+
+	if len(v.pending) >= viewBatchSize {
+		select {
+		case v.full <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// Start starts writing the views in the background.
+func (v *ViewRecorder) Start(ctx context.Context) {
+	ctx, v.cancel = context.WithCancel(ctx)
+
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+
+		ticker := time.NewTicker(viewFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				// the context is canceled, but the last views are still written
+				v.flush(context.WithoutCancel(ctx))
+				return
+			case <-ticker.C:
+				v.flush(ctx)
+			case <-v.full:
+				v.flush(ctx)
+			}
+		}
+	}()
+}
+
+// flush writes the pending views and forgets the visitors whose window has passed.
+func (v *ViewRecorder) flush(ctx context.Context) {
+	v.mu.Lock()
+	views := v.pending
+	v.pending = nil
+	now := time.Now()
+	for key, last := range v.seen {
+		if now.Sub(last) >= viewDedupWindow {
+			delete(v.seen, key)
+		}
+	}
+	v.mu.Unlock()
+
+	if len(views) == 0 {
+		return
+	}
+	if err := v.repo.RecordViews(ctx, views); err != nil {
+		// put the views back to retry them with the next batch
+		slog.ErrorContext(ctx, "failed to record item views: ", "error", err, "views", len(views))
+		v.mu.Lock()
+		v.pending = append(views, v.pending...)
+		v.mu.Unlock()
+	}
+}
+
+// Stop writes the pending views and stops the background goroutine.
+func (v *ViewRecorder) Stop() {
+	if v.cancel != nil {
+		v.cancel()
+	}
+	v.wg.Wait()
+}
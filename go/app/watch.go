@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// watcherBatchSize is the number of watchers loaded at once when fanning out a notification.
+const watcherBatchSize = 500
+
+// WatchItem is a handler to add an item to the watch list for POST /items/{item_id}/watch .
+func (s *Handlers) WatchItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		writeError(w, r, fmt.Errorf("failed to get item: %w", err))
+		return
+	}
+	if err != nil || !item.visibleTo(userID) {
+		writeError(w, r, errItemNotFound)
+		return
+	}
+
+	added, err := s.watchRepo.Watch(ctx, userID, itemID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to watch item: %w", err))
+		return
+	}
+	// watching again must not count as another like for the trending scores
+	if added {
+		s.recordEvent(ctx, itemID, ItemEventLike, userID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnwatchItem is a handler to remove an item from the watch list for DELETE /items/{item_id}/watch .
+func (s *Handlers) UnwatchItem(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	itemID, err := parseGetItemRequest(r)
+	if err != nil {
+		writeError(w, r, badRequest(err))
+		return
+	}
+
+	if err := s.watchRepo.Unwatch(r.Context(), userID, itemID); err != nil {
+		writeError(w, r, fmt.Errorf("failed to unwatch item: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWatchlist is a handler to return the items the user watches for GET /watchlist .
+func (s *Handlers) GetWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	items, err := s.watchRepo.ListItems(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("failed to get watched items: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Items []Item `json:"items"`
+	}{Items: items})
+}
+
+// notifyPriceDrop notifies every watcher of the item that its price has been lowered.
+// Watchers are loaded in batches so that items with many watchers do not need to fit in memory.
+func (s *Handlers) notifyPriceDrop(ctx context.Context, item *Item, oldPrice int) error {
+	message := fmt.Sprintf("Price dropped from %d to %d: %s", oldPrice, item.Price, item.Name)
+
+	var errs []error
+	after := 0
+	for {
+		userIDs, err := s.watchRepo.ListWatcherIDs(ctx, item.ID, after, watcherBatchSize)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		for _, userID := range userIDs {
+			err := s.notifier.Notify(ctx, &Notification{
+				UserID:  userID,
+				Type:    NotificationPriceDrop,
+				ItemID:  item.ID,
+				Message: message,
+			})
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(userIDs) < watcherBatchSize {
+			return errors.Join(errs...)
+		}
+		after = userIDs[len(userIDs)-1]
+	}
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WatchRepository is an interface to manage the items users watch.
+//
+//go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
+type WatchRepository interface {
+	// Watch adds the item to the watch list of the user. Watching twice is not an error,
+	// but it returns false if the item was already watched.
+	Watch(ctx context.Context, userID, itemID int) (bool, error)
+	// Unwatch removes the item from the watch list of the user. Unwatching an item not watched is not an error.
+	Unwatch(ctx context.Context, userID, itemID int) error
+	// ListItems returns the listed items the user watches, most recently watched first.
+	ListItems(ctx context.Context, userID int) ([]Item, error)
+	// ListWatcherIDs returns up to limit IDs of the users watching the item, greater than afterUserID in ascending order.
+	ListWatcherIDs(ctx context.Context, itemID, afterUserID, limit int) ([]int, error)
+}
+
+// watchRepository is an implementation of WatchRepository
+type watchRepository struct {
+	db *sql.DB
+}
+
+// NewWatchRepository creates a new watchRepository.
+func NewWatchRepository(database *sql.DB) WatchRepository {
+	return &watchRepository{db: database}
+}
+
+func (wr *watchRepository) Watch(ctx context.Context, userID, itemID int) (bool, error) {
+	res, err := wr.db.ExecContext(ctx, "INSERT OR IGNORE INTO watches (user_id, item_id, created_at) VALUES (?, ?, ?)",
+		userID, itemID, time.Now().UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to watch item: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to watch item: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (wr *watchRepository) Unwatch(ctx context.Context, userID, itemID int) error {
+	_, err := wr.db.ExecContext(ctx, "DELETE FROM watches WHERE user_id = ? AND item_id = ?", userID, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to unwatch item: %w", err)
+	}
+	return nil
+}
+
+func (wr *watchRepository) ListItems(ctx context.Context, userID int) ([]Item, error) {
+	rows, err := wr.db.QueryContext(ctx, "SELECT "+itemColumns+`
+		FROM items
+		JOIN watches ON watches.item_id = items.id
+		WHERE watches.user_id = ? AND `+listedItemsCondition+`
+		ORDER BY watches.created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watched items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		if err := scanItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate watched items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (wr *watchRepository) ListWatcherIDs(ctx context.Context, itemID, afterUserID, limit int) ([]int, error) {
+	rows, err := wr.db.QueryContext(ctx, `
+		SELECT user_id FROM watches
+		WHERE item_id = ? AND user_id > ?
+		ORDER BY user_id
+		LIMIT ?`, itemID, afterUserID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watchers: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan watcher: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate watchers: %w", err)
+	}
+
+	return userIDs, nil
+}
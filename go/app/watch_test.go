@@ -0,0 +1,274 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateItem(t *testing.T) {
+	t.Parallel()
+
+	type wants struct {
+		code     int
+		enqueued bool
+	}
+	cases := map[string]struct {
+		userID string
+		form   url.Values
+		// rules are the moderation rules the item is checked against
+		rules []ModerationRule
+		// injector is used to inject the expected calls to the mock
+		injector func(m *MockItemRepository)
+		wants
+	}{
+		"ok: price lowered": {
+			userID: "1",
+			form:   url.Values{"price": {"1500"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "jacket", SellerID: 1, Price: 2000, Status: ItemStatusOnSale}, nil)
+				m.EXPECT().Update(gomock.Any(), &Item{ID: 1, Name: "jacket", SellerID: 1, Price: 1500, Status: ItemStatusOnSale}).Return(nil)
+			},
+			wants: wants{code: http.StatusOK, enqueued: true},
+		},
+		"ok: price raised": {
+			userID: "1",
+			form:   url.Values{"name": {"new jacket"}, "price": {"2500"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "jacket", SellerID: 1, Price: 2000, Status: ItemStatusOnSale}, nil)
+				m.EXPECT().Update(gomock.Any(), &Item{ID: 1, Name: "new jacket", SellerID: 1, Price: 2500, Status: ItemStatusOnSale}).Return(nil)
+			},
+			wants: wants{code: http.StatusOK},
+		},
+		"ok: attribute changed on a listed item": {
+			userID: "1",
+			form:   url.Values{"attr.color": {"black"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "iPhone", CategoryID: 2, SellerID: 1, Price: 2000, Status: ItemStatusOnSale, Attributes: map[string]string{"brand": "Apple"}}, nil)
+				m.EXPECT().Update(gomock.Any(), &Item{ID: 1, Name: "iPhone", CategoryID: 2, SellerID: 1, Price: 2000, Status: ItemStatusOnSale, Attributes: map[string]string{"brand": "Apple", "color": "black"}}).Return(nil)
+			},
+			wants: wants{code: http.StatusOK},
+		},
+		"ng: invalid attribute on a listed item": {
+			userID: "1",
+			form:   url.Values{"attr.color": {"purple"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "iPhone", CategoryID: 2, SellerID: 1, Price: 2000, Status: ItemStatusOnSale, Attributes: map[string]string{"brand": "Apple"}}, nil)
+			},
+			wants: wants{code: http.StatusUnprocessableEntity},
+		},
+		"ng: category changed without its required attributes": {
+			userID: "1",
+			form:   url.Values{"category": {"smartphones"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "jacket", CategoryID: 1, SellerID: 1, Price: 2000, Status: ItemStatusOnSale}, nil)
+				m.EXPECT().GetCategoryID(gomock.Any(), "smartphones").Return(2, nil)
+			},
+			wants: wants{code: http.StatusUnprocessableEntity},
+		},
+		"ok: flagged name quarantined with the update": {
+			userID: "1",
+			form:   url.Values{"name": {"fake jacket"}},
+			rules:  []ModerationRule{{Word: "fake", Reason: "counterfeit"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "jacket", SellerID: 1, Price: 2000, Status: ItemStatusOnSale}, nil)
+				m.EXPECT().UpdateQuarantined(gomock.Any(), &Item{ID: 1, Name: "fake jacket", SellerID: 1, Price: 2000, Status: ItemStatusOnSale}, []string{"counterfeit"}).
+					DoAndReturn(func(_ context.Context, item *Item, _ []string) (*ModerationReview, error) {
+						item.Status = ItemStatusQuarantined
+						return &ModerationReview{ItemID: 1}, nil
+					})
+			},
+			wants: wants{code: http.StatusAccepted},
+		},
+		"ng: not the seller": {
+			userID: "2",
+			form:   url.Values{"price": {"1500"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "jacket", SellerID: 1, Price: 2000, Status: ItemStatusOnSale}, nil)
+			},
+			wants: wants{code: http.StatusForbidden},
+		},
+		"ng: sold out": {
+			userID: "1",
+			form:   url.Values{"price": {"1500"}},
+			injector: func(m *MockItemRepository) {
+				m.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, Name: "jacket", SellerID: 1, Price: 2000, Status: ItemStatusSoldOut}, nil)
+			},
+			wants: wants{code: http.StatusConflict},
+		},
+		"ng: nothing to update": {
+			userID:   "1",
+			form:     url.Values{},
+			injector: func(m *MockItemRepository) {},
+			wants:    wants{code: http.StatusBadRequest},
+		},
+		"ng: no user": {
+			form:     url.Values{"price": {"1500"}},
+			injector: func(m *MockItemRepository) {},
+			wants:    wants{code: http.StatusUnauthorized},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			tt.injector(mockIR)
+			// the queue is not started, so enqueued jobs stay in the channel
+			queue := NewJobQueue(1, 1)
+			mockMR := NewMockModerationRepository(ctrl)
+			mockMR.EXPECT().ListRules(gomock.Any(), gomock.Any()).Return(tt.rules, nil).AnyTimes()
+			mockAR := NewMockAttributeSchemaRepository(ctrl)
+			mockAR.EXPECT().GetSchema(gomock.Any(), 2).Return(defaultAttributeSchemas["smartphones"], nil).AnyTimes()
+			h := &Handlers{itemRepo: mockIR, jobQueue: queue, moderationRepo: mockMR, attributeSchemaRepo: mockAR}
+
+			req := httptest.NewRequest("PATCH", "/items/1", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("item_id", "1")
+			if tt.userID != "" {
+				req.Header.Set(userIDHeader, tt.userID)
+			}
+
+			rr := httptest.NewRecorder()
+			h.UpdateItem(rr, req)
+
+			if tt.wants.code != rr.Code {
+				t.Errorf("expected status code %d, got %d", tt.wants.code, rr.Code)
+			}
+			if got := len(queue.jobs) == 1; got != tt.wants.enqueued {
+				t.Errorf("expected enqueued %v, got %v", tt.wants.enqueued, got)
+			}
+		})
+	}
+}
+
+func TestWatchItem(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		added bool
+		// liked is whether a like is recorded for the trending scores
+		liked bool
+	}{
+		"ok: newly watched":   {added: true, liked: true},
+		"ok: already watched": {added: false, liked: false},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockIR := NewMockItemRepository(ctrl)
+			mockWR := NewMockWatchRepository(ctrl)
+			mockER := NewMockEventRepository(ctrl)
+			mockIR.EXPECT().GetByID(gomock.Any(), 1).Return(&Item{ID: 1, SellerID: 2, Status: ItemStatusOnSale}, nil)
+			mockWR.EXPECT().Watch(gomock.Any(), 3, 1).Return(tt.added, nil)
+			if tt.liked {
+				mockER.EXPECT().Record(gomock.Any(), &ItemEvent{ItemID: 1, Type: ItemEventLike, UserID: 3}).Return(nil)
+			}
+			h := &Handlers{itemRepo: mockIR, watchRepo: mockWR, eventRepo: mockER}
+
+			req := httptest.NewRequest("POST", "/items/1/watch", nil)
+			req.Header.Set(userIDHeader, "3")
+			req.SetPathValue("item_id", "1")
+			rr := httptest.NewRecorder()
+			h.WatchItem(rr, req)
+
+			if rr.Code != http.StatusNoContent {
+				t.Errorf("expected status code %d, got %d", http.StatusNoContent, rr.Code)
+			}
+		})
+	}
+}
+
+func TestNotifyPriceDrop(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockWR := NewMockWatchRepository(ctrl)
+	item := &Item{ID: 3, Name: "jacket", Price: 1500}
+
+	// the first batch is full, so the next one is loaded after the last user ID
+	full := make([]int, watcherBatchSize)
+	for i := range full {
+		full[i] = i + 1
+	}
+	gomock.InOrder(
+		mockWR.EXPECT().ListWatcherIDs(gomock.Any(), 3, 0, watcherBatchSize).Return(full, nil),
+		mockWR.EXPECT().ListWatcherIDs(gomock.Any(), 3, watcherBatchSize, watcherBatchSize).Return([]int{watcherBatchSize + 7}, nil),
+	)
+
+	notifier := &recordingNotifier{}
+	h := &Handlers{watchRepo: mockWR, notifier: notifier}
+
+	if err := h.notifyPriceDrop(context.Background(), item, 2000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(notifier.notifications) != watcherBatchSize+1 {
+		t.Fatalf("expected %d notifications, got %d", watcherBatchSize+1, len(notifier.notifications))
+	}
+	last := notifier.notifications[watcherBatchSize]
+	want := &Notification{UserID: watcherBatchSize + 7, Type: NotificationPriceDrop, ItemID: 3, Message: "Price dropped from 2000 to 1500: jacket"}
+	if diff := cmp.Diff(want, last); diff != "" {
+		t.Errorf("unexpected notification (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatchRepositoryListItems(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test")
+	}
+
+	db, closers, err := setupDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, c := range closers {
+			c()
+		}
+	})
+
+	itemRepo := &itemRepository{db: db}
+	watchRepo := NewWatchRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	items := []*Item{
+		{Name: "listed", CategoryID: 1, Image: "a.jpg", Status: ItemStatusOnSale, CreatedAt: now},
+		{Name: "quarantined", CategoryID: 1, Image: "a.jpg", Status: ItemStatusOnSale, CreatedAt: now},
+	}
+	for _, item := range items {
+		if err := itemRepo.Insert(ctx, item); err != nil {
+			t.Fatalf("failed to insert item: %v", err)
+		}
+		if _, err := watchRepo.Watch(ctx, 1, item.ID); err != nil {
+			t.Fatalf("failed to watch item: %v", err)
+		}
+	}
+	if _, err := NewModerationRepository(db, nil).Quarantine(ctx, items[1].ID, []string{"prohibited"}); err != nil {
+		t.Fatalf("failed to quarantine item: %v", err)
+	}
+
+	watched, err := watchRepo.ListItems(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed to list watched items: %v", err)
+	}
+	var names []string
+	for _, item := range watched {
+		names = append(names, item.Name)
+	}
+	if diff := cmp.Diff([]string{"listed"}, names); diff != "" {
+		t.Errorf("unexpected watched items (-want +got):\n%s", diff)
+	}
+}
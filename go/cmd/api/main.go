@@ -1,20 +1,31 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"mercari-build-training/app"
 	"os"
 )
 
-const (
-	port         = "9000"
-	imageDirPath = "images"
-)
-
 func main() {
 	// This is the entry point of the application.
-	// You don't need to modify this function.
-	os.Exit(app.Server{
-		Port:         port,
-		ImageDirPath: imageDirPath,
-	}.Run())
+	// The configuration is read from the flags, the environment and an optional file; see app.LoadConfig.
+	cfg, printConfig, err := app.LoadConfig(os.Args[1:], os.LookupEnv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	os.Exit(app.Server{Config: cfg}.Run())
 }
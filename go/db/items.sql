@@ -8,6 +8,228 @@ CREATE TABLE items (
     name TEXT NOT NULL,
     category_id INTEGER NOT NULL,
     image_name TEXT NOT NULL,
+    seller_id INTEGER NOT NULL DEFAULT 0,
+    price INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'on_sale',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    publish_at TIMESTAMP,
     FOREIGN KEY (category_id) REFERENCES categories(id)
 );
 
+CREATE INDEX idx_items_seller_id ON items(seller_id, created_at);
+
+CREATE INDEX idx_items_publish_at ON items(status, publish_at);
+
+CREATE TABLE comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE INDEX idx_comments_item_id ON comments(item_id, id);
+
+
+CREATE TABLE offers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    buyer_id INTEGER NOT NULL,
+    price INTEGER NOT NULL,
+    counter_price INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE INDEX idx_offers_item_id ON offers(item_id, status);
+
+CREATE TABLE orders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL UNIQUE,
+    buyer_id INTEGER NOT NULL,
+    seller_id INTEGER NOT NULL,
+    price INTEGER NOT NULL,
+    offer_id INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE TABLE conversations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL UNIQUE,
+    buyer_id INTEGER NOT NULL,
+    seller_id INTEGER NOT NULL,
+    buyer_last_read_id INTEGER NOT NULL DEFAULT 0,
+    seller_last_read_id INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (order_id) REFERENCES orders(id)
+);
+
+CREATE TABLE messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id INTEGER NOT NULL,
+    sender_id INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (conversation_id) REFERENCES conversations(id)
+);
+
+CREATE INDEX idx_messages_conversation_id ON messages(conversation_id, id);
+
+CREATE TABLE ratings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL,
+    rater_id INTEGER NOT NULL,
+    ratee_id INTEGER NOT NULL,
+    rating TEXT NOT NULL CHECK (rating IN ('good', 'normal', 'bad')),
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (order_id, rater_id),
+    FOREIGN KEY (order_id) REFERENCES orders(id)
+);
+
+CREATE INDEX idx_ratings_ratee_id ON ratings(ratee_id);
+
+CREATE TABLE follows (
+    follower_id INTEGER NOT NULL,
+    seller_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (follower_id, seller_id)
+);
+
+CREATE TABLE saved_searches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    keyword TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    min_price INTEGER NOT NULL DEFAULT 0,
+    max_price INTEGER NOT NULL DEFAULT 0,
+    attributes TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_saved_searches_user_id ON saved_searches(user_id);
+
+CREATE TABLE notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    item_id INTEGER NOT NULL DEFAULT 0,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, id);
+
+CREATE TABLE price_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    old_price INTEGER NOT NULL,
+    new_price INTEGER NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE INDEX idx_price_history_item_id ON price_history(item_id, id);
+
+CREATE TABLE watches (
+    user_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, item_id)
+);
+
+CREATE INDEX idx_watches_item_id ON watches(item_id, user_id);
+
+CREATE TABLE category_attributes (
+    category_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    options TEXT NOT NULL DEFAULT 'null',
+    position INTEGER NOT NULL,
+    PRIMARY KEY (category_id, name),
+    FOREIGN KEY (category_id) REFERENCES categories(id)
+);
+
+CREATE TABLE item_attributes (
+    item_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    value TEXT NOT NULL,
+    PRIMARY KEY (item_id, name),
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE INDEX idx_item_attributes_name_value ON item_attributes(name, value);
+
+CREATE TABLE moderation_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    category_id INTEGER NOT NULL DEFAULT 0,
+    word TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE moderation_reviews (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    reasons TEXT NOT NULL,
+    status TEXT NOT NULL,
+    reviewer_id INTEGER NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE INDEX idx_moderation_reviews_status ON moderation_reviews(status, id);
+
+CREATE TABLE moderation_decisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    moderator_id INTEGER NOT NULL DEFAULT 0,
+    action TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE INDEX idx_moderation_decisions_item_id ON moderation_decisions(item_id, id);
+
+CREATE TABLE reports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    reporter_id INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (item_id, reporter_id),
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE INDEX idx_reports_status_item_id ON reports(status, item_id);
+
+CREATE TABLE item_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    user_id INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE INDEX idx_item_events_created_at ON item_events(created_at);
+
+CREATE TABLE item_daily_views (
+    item_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (item_id, day),
+    FOREIGN KEY (item_id) REFERENCES items(id)
+);